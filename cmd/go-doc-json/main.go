@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"go/ast"
 	"log"
+	"os"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
@@ -21,7 +22,14 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		os.Exit(zpass.CacheMain(os.Args[2:]))
+	}
 	zpass.AddIgnoreFlagName("fix", "trace", "json")
+	zpass.RegisterCacheFlag()
+	zpass.RegisterFilterFlags()
+	zpass.RegisterPathStyleFlag()
+	zpass.ApplyFilter(Analyzer)
 	singlechecker.Main(Analyzer)
 }
 
@@ -29,6 +37,8 @@ const Doc = `go doc
 with flag "-debug v" for verbose
 `
 
+// container 依赖的 package 中的定义从 PackageIndex fact 中查找，不保存 pass，
+// 所以也可以使用 go vet -vettool 运行
 var container = &zpass.Container{}
 
 var Analyzer = &analysis.Analyzer{
@@ -41,6 +51,10 @@ var Analyzer = &analysis.Analyzer{
 	Run: run,
 }
 
+func init() {
+	zpass.SetCacheOptions(Analyzer, zpass.CacheOptions{Version: "1"})
+}
+
 func run(pass *analysis.Pass) (any, error) {
 	if zpass.IsTestPkg(pass.Pkg.Path()) {
 		return nil, nil
@@ -49,8 +63,6 @@ func run(pass *analysis.Pass) (any, error) {
 	if zpass.IsTrace() {
 		log.Printf("[%s] start check pkg: %s: %s\n", pass.Analyzer.Name, pass.Pkg.Name(), pass.Pkg.Path())
 	}
-	container.SetCurrentPass(pass)
-
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	nodeFilter := []ast.Node{
 		(*ast.File)(nil),
@@ -65,7 +77,8 @@ func run(pass *analysis.Pass) (any, error) {
 			return
 		}
 		if nf, ok := node.(*ast.File); ok {
-			ignore = checkIgnore(pass, nf)
+			// 生成的代码、-exclude 等已由 zpass.ApplyFilter 过滤
+			ignore = asthelper.IsGoTestFile(pass.Fset.File(nf.Pos()))
 			if ignore {
 				return
 			}
@@ -75,16 +88,6 @@ func run(pass *analysis.Pass) (any, error) {
 	return nil, nil
 }
 
-func checkIgnore(pass *analysis.Pass, nf *ast.File) bool {
-	tokenFile := pass.Fset.File(nf.Pos())
-
-	if !asthelper.IsGoFile(tokenFile) {
-		return true
-	}
-
-	return asthelper.IsGoTestFile(tokenFile)
-}
-
 func doNode(pass *analysis.Pass, node ast.Node) {
 	defer func() {
 		if re := recover(); re != nil {
@@ -354,6 +357,7 @@ func (d *Attr) AddUsage(txt string) {
 func newDocLine(pass *analysis.Pass) *DocLine {
 	return &DocLine{
 		Path: pass.Pkg.Path(),
+		pass: pass,
 	}
 }
 
@@ -365,6 +369,8 @@ type DocLine struct {
 	Attrs   []Attr   `json:",omitempty"` // 该类型包含哪几个公共属性
 	Params  []string `json:",omitempty"` // 方法的入参类型
 	Results []string `json:",omitempty"` // 方法的返回值类型
+
+	pass *analysis.Pass
 }
 
 func (d *DocLine) AddUsage(txt string) {
@@ -393,6 +399,7 @@ func (d *DocLine) String() string {
 	return string(bf)
 }
 
+// Print 输出到 stdout，使用 -cache 时会被缓存
 func (d *DocLine) Print() {
-	fmt.Println(d.String())
+	zpass.Println(d.pass, d.String())
 }
@@ -5,13 +5,64 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/singlechecker"
 
 	"github.com/fsgo/gocode/zanalysis/zpasses/gorecover"
 	"github.com/fsgo/gocode/zpass"
 )
 
+var summary = flag.String("summary", "table", "summary of checked goroutines: table|json|off\ntable is written to stderr, json to stdout and can't be used with other -format than text")
+
 func main() {
-	zpass.AddIgnoreFlagName("fix")
-	singlechecker.Main(gorecover.Analyzer)
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		os.Exit(zpass.CacheMain(os.Args[2:]))
+	}
+	// go vet -vettool 以及 -fix 时，使用 x/tools 的 driver
+	if zpass.StdDriverWanted(os.Args[1:]) {
+		zpass.RegisterFilterFlags()
+		zpass.RegisterPathStyleFlag()
+		zpass.ApplyFilter(gorecover.Analyzer)
+		singlechecker.Main(gorecover.Analyzer)
+		return
+	}
+	zpass.RegisterFlags(gorecover.Analyzer)
+	zpass.TryParseFlags()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *summary == "json" && zpass.OutputFormat() != zpass.FormatText {
+		fmt.Fprintf(os.Stderr, "-summary json can't be used with -format %s, both are written to stdout\n", zpass.OutputFormat())
+		os.Exit(1)
+	}
+
+	g, err := zpass.Analyze([]*analysis.Analyzer{gorecover.Analyzer}, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := zpass.PrintDiagnostics(g)
+	if err = printSummary(gorecover.NewSummary(zpass.Results[*gorecover.Result](g, gorecover.Analyzer))); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = 1
+	}
+	os.Exit(code)
+}
+
+func printSummary(s *gorecover.Summary) error {
+	switch *summary {
+	case "table":
+		return s.WriteTable(os.Stderr)
+	case "json":
+		return s.WriteJSON(os.Stdout)
+	case "off":
+		return nil
+	default:
+		return fmt.Errorf("unsupported summary %q", *summary)
+	}
 }
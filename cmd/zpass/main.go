@@ -0,0 +1,16 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package main
+
+import (
+	"github.com/fsgo/gocode/zpass"
+
+	// 注册 analyzer
+	_ "github.com/fsgo/gocode/zanalysis/zpasses/gorecover"
+)
+
+func main() {
+	zpass.Main()
+}
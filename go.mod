@@ -1,19 +1,21 @@
 module github.com/fsgo/gocode
 
-go 1.22
+go 1.22.0
 
 require (
 	github.com/fatih/color v1.17.0
 	github.com/fsgo/cmdutil v0.0.5
 	github.com/fsgo/fsgo v0.0.7-0.20240710132140-34d667eaee38
 	github.com/fsgo/gomodule v0.0.3
-	golang.org/x/mod v0.19.0
-	golang.org/x/tools v0.23.0
+	github.com/golangci/plugin-module-register v0.1.1
+	golang.org/x/mod v0.23.0
+	golang.org/x/tools v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sync v0.7.0 // indirect
-	golang.org/x/sys v0.22.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
 )
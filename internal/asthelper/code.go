@@ -5,36 +5,24 @@
 package asthelper
 
 import (
-	"bytes"
 	"fmt"
 	"go/ast"
-	"go/format"
-	"path/filepath"
-	"strings"
 
+	"github.com/fatih/color"
 	"golang.org/x/tools/go/analysis"
+
+	"github.com/fsgo/gocode/zpass"
 )
 
 func NodeLineNo(pass *analysis.Pass, node ast.Node) string {
 	p := node.Pos()
 	pos := pass.Fset.Position(p)
-	rn, _ := filepath.Rel(wd, pos.Filename)
-	return fmt.Sprintf("%s:%d", rn, pos.Line)
+	return fmt.Sprintf("%s:%d", zpass.RelPath(pos.Filename), pos.Line)
 }
 
+// NodeCode 返回 node 的源码片段，最多 line 行，输出到终端时颜色高亮 node，见 NodeSnippet
 func NodeCode(pass *analysis.Pass, node ast.Node, line int) string {
-	bf := &bytes.Buffer{}
-	format.Node(bf, pass.Fset, node)
-	lines := strings.SplitN(bf.String(), "\n", line+1)
-	if len(lines) > line {
-		lines = lines[:line]
-	}
-	lines = strings.Split(strings.TrimSpace(strings.Join(lines, "\n")), "\n")
-	pos := pass.Fset.Position(node.Pos())
-	for i := 0; i < len(lines); i++ {
-		lines[i] = fmt.Sprintf("%-5d %s", i+pos.Line, lines[i])
-	}
-	return strings.Join(lines, "\n")
+	return NodeSnippet(pass, node, SnippetOptions{MaxLines: line, Color: !color.NoColor})
 }
 
 func NodeCount(n ast.Node, fn func(c ast.Node) bool) int {
@@ -11,8 +11,6 @@ import (
 	"go/token"
 	"go/types"
 	"log"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -21,16 +19,6 @@ import (
 	"github.com/fsgo/gocode/zpass"
 )
 
-var wd string
-
-func init() {
-	c, err := os.Getwd()
-	if err != nil {
-		panic(err)
-	}
-	wd = c
-}
-
 func FindAstFileByObject(pass *analysis.Pass, ov types.Object) (f *ast.File, err error) {
 	p := ov.Pos()
 	tokenFile := pass.Fset.File(p)
@@ -90,10 +78,7 @@ func IsGoTestFile(f *token.File) bool {
 	return strings.HasSuffix(f.Name(), "_test.go")
 }
 
+// RelName 按照 -path-style 返回文件 name 的路径，见 zpass.RelPath
 func RelName(name string) string {
-	rn, err := filepath.Rel(wd, name)
-	if err != nil {
-		return name
-	}
-	return rn
+	return zpass.RelPath(name)
 }
@@ -0,0 +1,269 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package asthelper
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"golang.org/x/tools/go/analysis"
+)
+
+// SnippetOptions 源码片段的格式
+type SnippetOptions struct {
+	// Context 范围前后额外显示的行数
+	Context int
+
+	// MaxLines 范围内最多显示的行数，超过时省略之后的行，<=0 时不限制
+	MaxLines int
+
+	// Color 是否使用颜色高亮范围，否则在范围下方使用 "^~~~" 标记，
+	// 诊断信息中的颜色会被去掉，所以报告中使用的片段不应设置
+	Color bool
+}
+
+// Snippet 返回 [pos, end) 范围的源码片段，读取的是源文件的原始内容，注释、空行等都会保留：
+//
+//	27        wg.Add(1)
+//	28        go func() {
+//	          ^~~~~~~~~~~
+//	29            defer wg.Done()
+//
+// 每行以行号开头，行号不受 //line 指令影响，所有行共同的缩进会去掉，
+// 读取源文件失败或者文件内容已变化时返回空
+func Snippet(fset *token.FileSet, pos token.Pos, end token.Pos, opt SnippetOptions) string {
+	tf := fset.File(pos)
+	if tf == nil {
+		return ""
+	}
+	src := readSource(tf)
+	if src == nil {
+		return ""
+	}
+	if !end.IsValid() || end < pos || tf.Offset(end) > tf.Size() {
+		end = pos
+	}
+	startLine := tf.PositionFor(pos, false).Line
+	endLine := tf.PositionFor(end, false).Line
+	if end > pos && tf.PositionFor(end, false).Column == 1 {
+		// 范围以换行结束时，不包括下一行
+		endLine--
+	}
+	sp := &snippet{
+		tf:    tf,
+		src:   src,
+		start: tf.Offset(pos),
+		end:   tf.Offset(end),
+		opt:   opt,
+	}
+
+	last := min(endLine+opt.Context, tf.LineCount())
+	truncated := opt.MaxLines > 0 && endLine-startLine+1 > opt.MaxLines
+	if truncated {
+		last = startLine + opt.MaxLines - 1
+	}
+	for line := max(startLine-opt.Context, 1); line <= last; line++ {
+		sp.addLine(line)
+	}
+	return sp.render(truncated)
+}
+
+// NodeSnippet 返回 node 的源码片段，见 Snippet，读取源文件失败时返回使用 go/format 格式化的 node
+func NodeSnippet(pass *analysis.Pass, node ast.Node, opt SnippetOptions) string {
+	if code := Snippet(pass.Fset, node.Pos(), node.End(), opt); code != "" {
+		return code
+	}
+	return formatNode(pass, node, opt.MaxLines)
+}
+
+// snippet 一个源码片段中的行
+type snippet struct {
+	tf         *token.File
+	src        []byte
+	start, end int // 范围的 offset
+	opt        SnippetOptions
+	lines      []snippetLine
+}
+
+type snippetLine struct {
+	no   int
+	text string
+
+	// from、to 范围在 text 中的部分，颜色高亮时使用
+	from, to int
+
+	// underline 是否在下方标记范围，只标记范围的第一行和最后一行，
+	// 空的范围(如缺少的表达式)标记一个 "^"
+	underline bool
+}
+
+func (sp *snippet) addLine(no int) {
+	begin := sp.tf.Offset(sp.tf.LineStart(no))
+	finish := len(sp.src)
+	if no < sp.tf.LineCount() {
+		finish = sp.tf.Offset(sp.tf.LineStart(no+1)) - 1
+	}
+	text := strings.TrimRight(string(sp.src[begin:finish]), " \t\r")
+	l := snippetLine{no: no, text: text}
+	if begin <= sp.end && sp.start <= finish {
+		from := sp.start - begin
+		if from < 0 {
+			// 范围开始之后的行，从第一个非空白的字符开始
+			from = len(text) - len(strings.TrimLeft(text, " \t"))
+		}
+		l.from = min(from, len(text))
+		l.to = max(min(sp.end-begin, len(text)), l.from)
+		l.underline = sp.start >= begin || (sp.end <= finish && l.to > l.from)
+	}
+	sp.lines = append(sp.lines, l)
+}
+
+func (sp *snippet) render(truncated bool) string {
+	indent := sp.commonIndent()
+	hl := color.New(color.FgRed, color.Bold)
+	hl.EnableColor()
+
+	bf := &strings.Builder{}
+	for _, l := range sp.lines {
+		text := l.text[min(indent, len(l.text)):]
+		from, to := max(l.from-indent, 0), max(l.to-indent, 0)
+		if sp.opt.Color && to > from {
+			fmt.Fprintf(bf, "%-5d %s%s%s\n", l.no, text[:from], hl.Sprint(text[from:to]), text[to:])
+			continue
+		}
+		fmt.Fprintln(bf, strings.TrimRight(fmt.Sprintf("%-5d %s", l.no, text), " "))
+		if l.underline && !sp.opt.Color {
+			fmt.Fprintf(bf, "%-5s %s%s\n", "", blankOf(text[:from]), underline(text[from:to]))
+		}
+	}
+	if truncated {
+		fmt.Fprintf(bf, "%-5s ...\n", "")
+	}
+	return strings.TrimRight(bf.String(), "\n")
+}
+
+// commonIndent 所有非空行共同的缩进的长度
+func (sp *snippet) commonIndent() int {
+	var prefix string
+	first := true
+	for _, l := range sp.lines {
+		if l.text == "" {
+			continue
+		}
+		ws := l.text[:len(l.text)-len(strings.TrimLeft(l.text, " \t"))]
+		if first {
+			prefix, first = ws, false
+			continue
+		}
+		for !strings.HasPrefix(ws, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return len(prefix)
+}
+
+// blankOf 返回和 s 宽度相同的空白，保留 tab，使标记和代码对齐
+func blankOf(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// underline 返回 s 下方的标记 "^~~~"，标记中 tab 的宽度和代码中一致
+func underline(s string) string {
+	if s == "" {
+		return "^"
+	}
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case i == 0:
+			b.WriteByte('^')
+		case r == '\t':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte('~')
+		}
+	}
+	return b.String()
+}
+
+// maxSources sources 中最多缓存的文件数，同一个文件中的诊断信息一般是连续报告的，
+// 所以只缓存最近读取的文件
+const maxSources = 16
+
+// sources 最近读取的源文件内容，超过 maxSources 个时删除最早读取的
+//
+// 使用 *token.File 作为 key，文件修改后重新解析(如在 gopls 等常驻进程中)得到的是新的 token.File，
+// 会重新读取，不会因为文件大小不变而使用旧的内容
+var sources struct {
+	mu    sync.Mutex
+	files []*token.File
+	data  map[*token.File][]byte
+}
+
+// readSource 返回 tf 对应的源文件的内容，读取失败或者大小和 tf 不一致时返回 nil
+func readSource(tf *token.File) []byte {
+	sources.mu.Lock()
+	content, ok := sources.data[tf]
+	sources.mu.Unlock()
+	if ok {
+		return sourceOf(tf, content)
+	}
+
+	content, err := os.ReadFile(tf.Name())
+	if err != nil {
+		content = []byte{}
+	}
+	sources.mu.Lock()
+	defer sources.mu.Unlock()
+	if _, ok = sources.data[tf]; !ok {
+		if sources.data == nil {
+			sources.data = make(map[*token.File][]byte)
+		}
+		if len(sources.files) >= maxSources {
+			delete(sources.data, sources.files[0])
+			sources.files = sources.files[1:]
+		}
+		sources.files = append(sources.files, tf)
+		sources.data[tf] = content
+	}
+	return sourceOf(tf, content)
+}
+
+func sourceOf(tf *token.File, content []byte) []byte {
+	if len(content) == 0 || len(content) != tf.Size() {
+		return nil
+	}
+	return content
+}
+
+// formatNode 使用 go/format 格式化 node，最多返回 line 行
+func formatNode(pass *analysis.Pass, node ast.Node, line int) string {
+	bf := &bytes.Buffer{}
+	format.Node(bf, pass.Fset, node)
+	lines := strings.Split(strings.TrimSpace(bf.String()), "\n")
+	if line > 0 && len(lines) > line {
+		lines = lines[:line]
+	}
+	pos := pass.Fset.Position(node.Pos())
+	for i := 0; i < len(lines); i++ {
+		lines[i] = fmt.Sprintf("%-5d %s", i+pos.Line, lines[i])
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,168 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package asthelper
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const snippetSrc = `package demo
+
+func fn() {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		work()
+	}()
+	wg.Wait()
+}
+`
+
+// addSnippetFile 将 src 写入临时文件，并添加到 fset 中
+func addSnippetFile(t *testing.T, fset *token.FileSet, src string) *token.File {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "demo.go")
+	if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tf := fset.AddFile(name, -1, len(src))
+	tf.SetLinesForContent([]byte(src))
+	return tf
+}
+
+func TestSnippet(t *testing.T) {
+	fset := token.NewFileSet()
+	tf := addSnippetFile(t, fset, snippetSrc)
+	// rng 返回 src 中第 n 个(从 0 开始) sub 的范围
+	rng := func(sub string, n int) (token.Pos, token.Pos) {
+		idx := -1
+		for i := 0; i <= n; i++ {
+			next := strings.Index(snippetSrc[idx+1:], sub)
+			if next < 0 {
+				t.Fatalf("%q not found", sub)
+			}
+			idx += next + 1
+		}
+		return tf.Pos(idx), tf.Pos(idx + len(sub))
+	}
+	goStmt := "go func() {\n\t\tdefer wg.Done()\n\t\twork()\n\t}()"
+
+	tests := []struct {
+		name string
+		sub  string
+		opt  SnippetOptions
+		want string
+	}{
+		{
+			name: "single line",
+			sub:  "wg.Done()",
+			want: "6     defer wg.Done()\n" +
+				"            ^~~~~~~~~",
+		},
+		{
+			// 共同的缩进是一个 tab，标记中保留 tab 以和代码对齐
+			name: "context",
+			sub:  "wg.Done()",
+			opt:  SnippetOptions{Context: 1},
+			want: "5     go func() {\n" +
+				"6     \tdefer wg.Done()\n" +
+				"      \t      ^~~~~~~~~\n" +
+				"7     \twork()",
+		},
+		{
+			name: "multi lines",
+			sub:  goStmt,
+			want: "5     go func() {\n" +
+				"      ^~~~~~~~~~~\n" +
+				"6     \tdefer wg.Done()\n" +
+				"7     \twork()\n" +
+				"8     }()\n" +
+				"      ^~~",
+		},
+		{
+			name: "truncated",
+			sub:  goStmt,
+			opt:  SnippetOptions{Context: 1, MaxLines: 2},
+			want: "4     wg.Add(1)\n" +
+				"5     go func() {\n" +
+				"      ^~~~~~~~~~~\n" +
+				"6     \tdefer wg.Done()\n" +
+				"      ...",
+		},
+		{
+			name: "empty range",
+			sub:  "",
+			want: "7     work()\n" +
+				"      ^",
+		},
+		{
+			name: "color",
+			sub:  "wg.Done()",
+			opt:  SnippetOptions{Color: true},
+			want: "6     defer \x1b[31;1mwg.Done()\x1b[0;22m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pos, end token.Pos
+			if tt.sub == "" {
+				pos, _ = rng("work()", 0)
+				end = pos
+			} else {
+				pos, end = rng(tt.sub, 0)
+			}
+			if got := Snippet(fset, pos, end, tt.opt); got != tt.want {
+				t.Fatalf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	// 文件内容已变化时返回空
+	changed := addSnippetFile(t, fset, snippetSrc+"\n")
+	if err := os.WriteFile(changed.Name(), []byte(snippetSrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Snippet(fset, changed.Pos(0), changed.Pos(7), SnippetOptions{}); got != "" {
+		t.Fatalf("file changed: got %q, want empty", got)
+	}
+}
+
+func TestSnippetEdited(t *testing.T) {
+	fset := token.NewFileSet()
+	tf := addSnippetFile(t, fset, snippetSrc)
+	if got, want := Snippet(fset, tf.Pos(0), tf.Pos(7), SnippetOptions{}), "1     package demo\n      ^~~~~~~"; got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// 修改文件，大小不变，重新解析后读取的是新的内容
+	edited := strings.Replace(snippetSrc, "package demo", "package dem0", 1)
+	if err := os.WriteFile(tf.Name(), []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tf2 := fset.AddFile(tf.Name(), -1, len(edited))
+	tf2.SetLinesForContent([]byte(edited))
+	if got, want := Snippet(fset, tf2.Pos(0), tf2.Pos(7), SnippetOptions{}), "1     package dem0\n      ^~~~~~~"; got != want {
+		t.Fatalf("edited: got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReadSourceBounded(t *testing.T) {
+	fset := token.NewFileSet()
+	for i := 0; i < maxSources*2; i++ {
+		tf := addSnippetFile(t, fset, snippetSrc)
+		if readSource(tf) == nil {
+			t.Fatalf("readSource(%s): got nil", tf.Name())
+		}
+	}
+	sources.mu.Lock()
+	defer sources.mu.Unlock()
+	if len(sources.files) > maxSources || len(sources.data) > maxSources {
+		t.Fatalf("got %d files, %d contents cached, want at most %d", len(sources.files), len(sources.data), maxSources)
+	}
+}
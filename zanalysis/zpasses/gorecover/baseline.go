@@ -0,0 +1,366 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/fsgo/gocode/zpass"
+)
+
+var (
+	baselineFile   string
+	baselineUpdate bool
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&baselineFile, "baseline", "", "baseline file, e.g. gorecover.baseline.json\nfindings recorded in it are not reported")
+	Analyzer.Flags.BoolVar(&baselineUpdate, "baseline-update", false, "write current findings to the -baseline file")
+}
+
+// Finding 基线文件中记录的一个问题
+// 使用代码的 hash 而不是行号，代码位置变化后依然可以匹配
+type Finding struct {
+	Package string // 所在 package
+	Func    string // 所在函数，如 Run，(*Server).Start
+	Hash    string // 所在语句代码的 hash
+	Message string // 问题描述，其中的位置(如 "func literal at a.go:12:3")去掉了行号和列号
+}
+
+func (f Finding) key() string {
+	return f.Package + "\n" + f.Func + "\n" + f.Hash + "\n" + f.Message
+}
+
+// Baseline 基线文件
+type Baseline struct {
+	Findings []Finding
+}
+
+// LoadBaseline 读取基线文件，文件不存在时返回空的基线
+func LoadBaseline(name string) (*Baseline, error) {
+	bl := &Baseline{}
+	bf, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return bl, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(bf, bl); err != nil {
+		return nil, err
+	}
+	// 之前的版本写入的问题描述中可能有行号
+	for i := range bl.Findings {
+		bl.Findings[i].Message = findingMessage(bl.Findings[i].Message)
+	}
+	return bl, nil
+}
+
+// WriteFile 排序后写入文件，先写入临时文件再重命名，不会出现写了一半的文件
+func (bl *Baseline) WriteFile(name string) error {
+	sort.Slice(bl.Findings, func(i, j int) bool {
+		return bl.Findings[i].key() < bl.Findings[j].key()
+	})
+	bf, err := json.MarshalIndent(bl, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(append(bf, '\n')); err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if err1 := tmp.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Merge 使用 current 中的问题替换基线中对应 package 的问题，其他 package 的问题保持不变
+func (bl *Baseline) Merge(current map[string][]Finding) {
+	findings := bl.Findings[:0]
+	for _, f := range bl.Findings {
+		if _, ok := current[f.Package]; !ok {
+			findings = append(findings, f)
+		}
+	}
+	for _, fs := range current {
+		findings = append(findings, fs...)
+	}
+	bl.Findings = findings
+}
+
+// baselines 所有 package 共用的基线，按照 package 分组
+type baselines struct {
+	mux      sync.Mutex
+	loaded   bool
+	err      error
+	packages map[string]map[string]int // pkg -> key -> 数量
+
+	// current -baseline-update 时，每次分析 package 时的问题，
+	// 同一个 package 可能会被分析多次(如包含测试文件时)，写入时合并
+	current map[*types.Package][]Finding
+}
+
+var baseline = &baselines{}
+
+func (b *baselines) load() error {
+	if b.loaded {
+		return b.err
+	}
+	b.loaded = true
+	b.packages = make(map[string]map[string]int)
+	b.current = make(map[*types.Package][]Finding)
+	if baselineUpdate {
+		return nil
+	}
+	bl, err := LoadBaseline(baselineFile)
+	if err != nil {
+		b.err = err
+		return err
+	}
+	for _, f := range bl.Findings {
+		if b.packages[f.Package] == nil {
+			b.packages[f.Package] = make(map[string]int)
+		}
+		b.packages[f.Package][f.key()]++
+	}
+	return nil
+}
+
+// filter 返回 package 的过滤器，返回 true 表示在基线中
+// 更新基线时，记录所有的问题
+func (b *baselines) filter(pkg *types.Package) (func(f Finding) bool, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+	// 同一个 package 可能会被分析多次(如包含测试文件时)，每次都重新计数
+	counts := make(map[string]int, len(b.packages[pkg.Path()]))
+	for k, v := range b.packages[pkg.Path()] {
+		counts[k] = v
+	}
+	if baselineUpdate {
+		b.current[pkg] = []Finding{}
+	}
+	return func(f Finding) bool {
+		if baselineUpdate {
+			b.mux.Lock()
+			b.current[pkg] = append(b.current[pkg], f)
+			b.mux.Unlock()
+			return false
+		}
+		k := f.key()
+		if counts[k] > 0 {
+			counts[k]--
+			return true
+		}
+		return false
+	}, nil
+}
+
+// findings 返回已记录的每个 package 的问题，
+// 同一个 package 分析了多次时，每个问题的数量为各次中最多的
+func (b *baselines) findings() map[string][]Finding {
+	type counted struct {
+		f     Finding
+		count int
+	}
+	merged := make(map[string]map[string]*counted)
+	for pkg, fs := range b.current {
+		pm := merged[pkg.Path()]
+		if pm == nil {
+			pm = make(map[string]*counted)
+			merged[pkg.Path()] = pm
+		}
+		counts := make(map[string]int)
+		for _, f := range fs {
+			counts[f.key()]++
+			if c, ok := pm[f.key()]; ok {
+				c.count = max(c.count, counts[f.key()])
+			} else {
+				pm[f.key()] = &counted{f: f, count: 1}
+			}
+		}
+	}
+	result := make(map[string][]Finding, len(merged))
+	for pkg, pm := range merged {
+		fs := []Finding{}
+		for _, c := range pm {
+			for i := 0; i < c.count; i++ {
+				fs = append(fs, c.f)
+			}
+		}
+		result[pkg] = fs
+	}
+	return result
+}
+
+// save -baseline-update 时，将已分析的 package 的问题合并到基线文件中，未分析的 package 保持不变
+//
+// 使用 zpass 的 driver 时，所有 package 分析完后写入一次；
+// go vet 时每个 package 在单独的进程中分析，每个进程写入一次，通过锁文件避免并发写入
+func (b *baselines) save() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if len(b.current) == 0 {
+		return nil
+	}
+	unlock, err := lockFile(baselineFile + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	bl, err := LoadBaseline(baselineFile)
+	if err != nil {
+		return err
+	}
+	bl.Merge(b.findings())
+	// 不清空 current：并行分析时，其他 package 可能还未分析完，之后再次写入时需要完整的问题
+	return bl.WriteFile(baselineFile)
+}
+
+// saveBaseline 注册到 zpass.OnFinish，所有 package 分析完后写入基线文件
+func saveBaseline() error {
+	if baselineFile == "" || !baselineUpdate {
+		return nil
+	}
+	return baseline.save()
+}
+
+// lockFile 创建锁文件 name，已存在时等待，超过 lockStale 未释放的锁文件认为已失效
+func lockFile(name string) (unlock func(), err error) {
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(name) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err1 := os.Stat(name); err1 == nil && time.Since(info.ModTime()) > lockStale {
+			_ = os.Remove(name)
+			continue
+		}
+		if time.Since(start) > lockStale {
+			return nil, fmt.Errorf("wait for lock file %s timeout", name)
+		}
+	}
+}
+
+const lockStale = 30 * time.Second
+
+// newFinding 生成诊断信息对应的问题
+func newFinding(pass *analysis.Pass, d analysis.Diagnostic) Finding {
+	f := Finding{
+		Package: pass.Pkg.Path(),
+		Message: findingMessage(d.Message),
+	}
+	file := fileOf(pass, d.Pos)
+	if file == nil {
+		return f
+	}
+	path, _ := astutil.PathEnclosingInterval(file, d.Pos, d.Pos)
+	var stmt ast.Node
+	for _, node := range path {
+		switch vt := node.(type) {
+		case ast.Stmt:
+			if stmt == nil {
+				stmt = vt
+			}
+		case *ast.FuncDecl:
+			f.Func = funcDeclName(vt)
+		}
+	}
+	if stmt == nil && len(path) > 0 {
+		stmt = path[0]
+	}
+	if stmt != nil {
+		f.Hash = codeHash(pass.Fset, stmt)
+	}
+	return f
+}
+
+var (
+	messageIDReg  = regexp.MustCompile(`^\[\d+\]\s*`)
+	messagePosReg = regexp.MustCompile(`(\.go):\d+(:\d+)?`)
+)
+
+// findingMessage 去掉诊断信息中的警告前缀、序号、代码，以及位置中的行号和列号，
+// 如 "possible func literal at a.go:12:3" 为 "possible func literal at a.go"，
+// 其他代码的位置变化后依然可以匹配
+func findingMessage(msg string) string {
+	msg, _, _ = strings.Cut(msg, "\n")
+	msg = strings.TrimPrefix(msg, warningPrefix)
+	msg = messagePosReg.ReplaceAllString(msg, "$1")
+	return strings.TrimSpace(messageIDReg.ReplaceAllString(msg, ""))
+}
+
+// isLocalFile 是否是当前目录下的文件，和 -path-style 无关
+func isLocalFile(f *token.File) bool {
+	if f == nil {
+		return false
+	}
+	rn := zpass.PathRelative.Render(f.Name())
+	return !filepath.IsAbs(rn) && !strings.HasPrefix(rn, "..")
+}
+
+func fileOf(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, f := range pass.Files {
+		if f.FileStart <= pos && pos <= f.FileEnd {
+			return f
+		}
+	}
+	return nil
+}
+
+func funcDeclName(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return fd.Name.Name
+	}
+	var bf bytes.Buffer
+	_ = format.Node(&bf, token.NewFileSet(), fd.Recv.List[0].Type)
+	recv := bf.String()
+	if strings.HasPrefix(recv, "*") {
+		recv = "(" + recv + ")"
+	}
+	return recv + "." + fd.Name.Name
+}
+
+// codeHash 格式化后代码的 hash，不受空白和行号的影响
+func codeHash(fset *token.FileSet, node ast.Node) string {
+	var bf bytes.Buffer
+	_ = format.Node(&bf, fset, node)
+	code := strings.Join(strings.Fields(bf.String()), " ")
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,176 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setBaselineFlags 设置 -baseline、-baseline-update 参数，测试结束后恢复
+func setBaselineFlags(t *testing.T, name string, update bool) {
+	file, upd := baselineFile, baselineUpdate
+	baselineFile, baselineUpdate = name, update
+	t.Cleanup(func() {
+		baselineFile, baselineUpdate = file, upd
+	})
+}
+
+func finding(pkg string, hash string) Finding {
+	return Finding{Package: pkg, Func: "fn", Hash: hash, Message: "goroutine not recovered"}
+}
+
+func TestLoadBaseline(t *testing.T) {
+	dir := t.TempDir()
+
+	bl, err := LoadBaseline(filepath.Join(dir, "missing.json"))
+	if err != nil || len(bl.Findings) != 0 {
+		t.Fatalf("missing file: got %v, %v, want empty baseline", bl, err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err = os.WriteFile(bad, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadBaseline(bad); err == nil {
+		t.Fatal("invalid json: want error")
+	}
+
+	name := filepath.Join(dir, "baseline.json")
+	want := &Baseline{Findings: []Finding{finding("b", "2"), finding("a", "1")}}
+	if err = want.WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadBaseline(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 写入时排序
+	sorted := []Finding{finding("a", "1"), finding("b", "2")}
+	if !reflect.DeepEqual(got.Findings, sorted) {
+		t.Fatalf("got %v, want %v", got.Findings, sorted)
+	}
+}
+
+func TestFindingMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{msg: "[3] goroutine not recovered, func type is *ast.FuncLit", want: "goroutine not recovered, func type is *ast.FuncLit"},
+		{msg: warningPrefix + "[1] goroutine not recovered\ncode:", want: "goroutine not recovered"},
+		{
+			msg:  "[1] goroutine not recovered, possible func literal at a/b.go:12:3: not recovered",
+			want: "goroutine not recovered, possible func literal at a/b.go: not recovered",
+		},
+		{msg: "func literal at /x/a.go:7:2 not recovered", want: "func literal at /x/a.go not recovered"},
+		// 已处理过的不变
+		{msg: "possible func literal at a/b.go: not recovered", want: "possible func literal at a/b.go: not recovered"},
+	}
+	for _, tt := range tests {
+		if got := findingMessage(tt.msg); got != tt.want {
+			t.Errorf("findingMessage(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestBaselineFilter(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "baseline.json")
+	bl := &Baseline{Findings: []Finding{finding("a", "1"), finding("a", "1"), finding("a", "2")}}
+	if err := bl.WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+	setBaselineFlags(t, name, false)
+
+	b := &baselines{}
+	pkgA := types.NewPackage("a", "a")
+	tests := []struct {
+		f    Finding
+		want bool
+	}{
+		{f: finding("a", "1"), want: true},
+		{f: finding("a", "1"), want: true},
+		// 基线中只有 2 个
+		{f: finding("a", "1"), want: false},
+		{f: finding("a", "2"), want: true},
+		{f: finding("a", "3"), want: false},
+	}
+	in, err := b.filter(pkgA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		if got := in(tt.f); got != tt.want {
+			t.Errorf("#%d %v: got %v, want %v", i, tt.f, got, tt.want)
+		}
+	}
+
+	// 其他 package 中相同的问题不在基线中
+	inB, err := b.filter(types.NewPackage("b", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inB(finding("a", "1")) || inB(finding("b", "1")) {
+		t.Error("package b: want not in baseline")
+	}
+
+	// 再次分析同一个 package 时重新计数
+	in, _ = b.filter(pkgA)
+	if !in(finding("a", "1")) {
+		t.Error("analyzed again: want in baseline")
+	}
+}
+
+func TestBaselineUpdate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "baseline.json")
+	old := &Baseline{Findings: []Finding{finding("a", "old"), finding("b", "1"), finding("c", "1")}}
+	if err := old.WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+	setBaselineFlags(t, name, true)
+
+	b := &baselines{}
+	// package a 分析了两次(不含、包含测试文件)，每个问题取最多的数量
+	for _, fs := range [][]Finding{
+		{finding("a", "1")},
+		{finding("a", "1"), finding("a", "2"), finding("a", "2")},
+	} {
+		record, err := b.filter(types.NewPackage("a", "a"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range fs {
+			if record(f) {
+				t.Fatal("updating: want not filtered")
+			}
+		}
+	}
+	// package c 已没有问题
+	if _, err := b.filter(types.NewPackage("c", "c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.save(); err != nil {
+		t.Fatal(err)
+	}
+	// 再次写入(如 go vet 时每个 package 写入一次)，结果不变
+	if err := b.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadBaseline(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Finding{finding("a", "1"), finding("a", "2"), finding("a", "2"), finding("b", "1")}
+	if !reflect.DeepEqual(got.Findings, want) {
+		t.Fatalf("got %v, want %v", got.Findings, want)
+	}
+	if _, err = os.Stat(name + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("lock file not removed: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// maxDepth 最多跟踪的封装函数层级，由 -max-depth 参数指定，可以被配置文件覆盖
+var maxDepth = 10
+
+// resolver 返回函数 fn 的 RecoversFact，没有时返回 nil
+type resolver func(fn *types.Func) *RecoversFact
+
+// factResolver 只使用已导出的 RecoversFact 判断
+func factResolver(pass *analysis.Pass) resolver {
+	return func(fn *types.Func) *RecoversFact {
+		return importFact(pass, fn)
+	}
+}
+
+// bodyRecovered 判断函数体在执行任何用户代码之前，是否已 recover
+//
+// 已 recover 的情况：
+//   - 函数体内有 defer func(){ recover() }()
+//   - 只调用了已 recover 的函数：调用之前没有调用其他函数(用户代码)，
+//     调用之后也没有调用其他函数或者 panic，因为其 recover() 只保护它自己执行的代码
+//
+// 如下面的 Go 函数，只调用了已 recover 的 safe.Run：
+//
+//	func Go(ctx context.Context, fn func()) {
+//		defer wg.Done()
+//		safe.Run(fn)
+//	}
+//
+// 而 safe.Run(fn); risky() 中的 risky() 未 recover
+//
+// 已 recover 时，返回值 node 为函数体内 recover() 或者已 recover 函数的调用所在的节点
+// 未 recover 时，返回值 reason 为原因
+func bodyRecovered(pass *analysis.Pass, body *ast.BlockStmt, resolve resolver) (rf *RecoversFact, node ast.Node, reason string) {
+	// func body empty when with go:linkname
+	if body == nil {
+		return nil, nil, ""
+	}
+	if node, at := blockRecovered(pass, body, resolve); node != nil {
+		return &RecoversFact{At: at}, node, ""
+	}
+	for i, stmt := range body.List {
+		switch vt := stmt.(type) {
+		case *ast.DeferStmt:
+			// defer 的函数在最后执行
+			continue
+		case *ast.ExprStmt:
+			// safe.Run(fn)
+			ce, ok := vt.X.(*ast.CallExpr)
+			if !ok || hasCallInArgs(pass, ce) {
+				break
+			}
+			fn := typeutil.StaticCallee(pass.TypesInfo, ce)
+			if fn == nil {
+				break
+			}
+			if rf, reason = chainTo(pass, fn, resolve(fn)); rf == nil {
+				return nil, nil, reason
+			}
+			if call := callAfter(pass, body.List[i+1:]); call != nil {
+				return nil, nil, "call " + types.ExprString(call.Fun) + " after " + funcName(fn)
+			}
+			return rf, ce, ""
+		}
+		if call := firstCall(pass, stmt); call != nil {
+			return nil, nil, "call " + types.ExprString(call.Fun) + " before recover"
+		}
+	}
+	return nil, nil, ""
+}
+
+// chainTo 返回调用函数 fn 时的调用链，fn 未 recover 或者调用链过长时返回 nil
+// fn 在其他 module 中时，未 recover 的原因中包含其 module，以便判断是在本地还是上游修复
+func chainTo(pass *analysis.Pass, fn *types.Func, rf *RecoversFact) (*RecoversFact, string) {
+	name := funcName(fn)
+	if rf == nil || !rf.Recovered() {
+		if mf := externalModule(pass, fn); mf != nil {
+			return nil, name + " not recovered, in untrusted module " + mf.String()
+		}
+		return nil, name + " not recovered"
+	}
+	if cfg, _ := getConfig(pass); cfg != nil && len(rf.Chain)+1 > cfg.MaxDepth {
+		return nil, name + " exceeds max depth"
+	}
+	chain := make([]string, 0, len(rf.Chain)+1)
+	chain = append(chain, name)
+	chain = append(chain, rf.Chain...)
+	return &RecoversFact{At: rf.At, Chain: chain}, ""
+}
+
+// callAfter 返回已 recover 的函数调用之后的语句 list 中，第一个会执行的函数调用或者 panic，
+// 和调用之前一样，不检查 defer 语句
+func callAfter(pass *analysis.Pass, list []ast.Stmt) *ast.CallExpr {
+	for _, stmt := range list {
+		if _, ok := stmt.(*ast.DeferStmt); ok {
+			continue
+		}
+		if call := firstCall(pass, stmt); call != nil {
+			return call
+		}
+	}
+	return nil
+}
+
+// firstCall 返回 node 中第一个会执行的函数调用，builtin 函数和类型转换除外
+// panic 也是函数调用
+func firstCall(pass *analysis.Pass, node ast.Node) *ast.CallExpr {
+	var found *ast.CallExpr
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		switch vt := n.(type) {
+		case *ast.FuncLit:
+			// 只是定义，不会执行
+			return false
+		case *ast.CallExpr:
+			if tv, ok := pass.TypesInfo.Types[vt.Fun]; ok && tv.IsType() {
+				return true
+			}
+			if bt, ok := typeutil.Callee(pass.TypesInfo, vt).(*types.Builtin); ok && bt.Name() != "panic" {
+				return true
+			}
+			found = vt
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// hasCallInArgs 函数调用的参数中是否有其他函数调用，如 safe.Run(newTask())
+func hasCallInArgs(pass *analysis.Pass, ce *ast.CallExpr) bool {
+	for _, arg := range ce.Args {
+		if firstCall(pass, arg) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func funcName(fn *types.Func) string {
+	return fn.Origin().FullName()
+}
+
+func chainString(chain []string) string {
+	return strings.Join(chain, " -> ")
+}
@@ -0,0 +1,349 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"gopkg.in/yaml.v3"
+
+	"github.com/fsgo/gocode/zpass"
+)
+
+// Config gorecover 的配置，可以从 .zpass.yaml 的 go_recover 中读取，
+// 也可以从当前目录下的 .gorecover.yaml 或者 .gorecover.json 中读取
+//
+//	safe_funcs:
+//	  - github.com/sourcegraph/conc.(*WaitGroup).Go
+//	launchers:
+//	  - github.com/fsgo/fsgo/fssync.(*WaitGroup).Go
+//	  - github.com/my/timer.After#1
+//	trusted_modules:
+//	  - std
+//	  - golang.org/x
+type Config struct {
+	// SafeFuncs 可信的函数，认为其已 recover
+	SafeFuncs []string `json:"safe_funcs" yaml:"safe_funcs"`
+
+	// Launchers 会启动 goroutine 的函数，其参数中的函数会和 go 语句一样检查
+	// 默认检查所有 func 类型的参数，也可以使用 "#序号" 指定参数，序号从 0 开始
+	Launchers []string `json:"launchers" yaml:"launchers"`
+
+	// NoDefaultLaunchers 不使用内置的 DefaultLaunchers
+	NoDefaultLaunchers bool `json:"no_default_launchers" yaml:"no_default_launchers"`
+
+	// ReportFuncs 用于报告 recover 到的 panic 的函数，如日志、监控打点，支持 * 通配符
+	// 调用了这些函数的 recover 不认为是吞掉了 panic，内置的 DefaultReportFuncs 总是生效
+	ReportFuncs []string `json:"report_funcs" yaml:"report_funcs"`
+
+	// TrustedModules 可信的 module，其中的函数认为已 recover，如 std、golang.org/x
+	// 支持 module 路径前缀和 * 通配符，std 表示标准库
+	TrustedModules []string `json:"trusted_modules" yaml:"trusted_modules"`
+
+	// MaxDepth 最多跟踪的封装函数层级，默认为 -max-depth 参数的值
+	MaxDepth int `json:"max_depth" yaml:"max_depth" flag:"max-depth"`
+
+	// Swallow 是否检查吞掉 panic 的 recover()，默认为 -swallow 参数的值
+	Swallow bool `json:"swallow" yaml:"swallow" flag:"swallow"`
+}
+
+// DefaultLaunchers 内置的会启动 goroutine 的函数
+var DefaultLaunchers = []string{
+	"context.AfterFunc#1",
+	"time.AfterFunc#1",
+	"sync.(*WaitGroup).Go",
+	"golang.org/x/sync/errgroup.(*Group).Go",
+	"golang.org/x/sync/errgroup.(*Group).TryGo",
+	"golang.org/x/sync/singleflight.(*Group).DoChan#1",
+}
+
+// DefaultReportFuncs 内置的用于报告 panic 的函数
+var DefaultReportFuncs = []string{
+	"log.*",
+	"log/slog.*",
+	"fmt.Print*",
+	"fmt.Fprint*",
+	"runtime/debug.PrintStack",
+	"testing.*",
+}
+
+// passThroughFuncs 返回的函数会调用其 func 类型参数的函数，
+// 如 go sync.OnceFunc(fn)()，实际在 goroutine 中运行的是 fn
+var passThroughFuncs = []string{
+	"sync.OnceFunc",
+	"sync.OnceValue",
+	"sync.OnceValues",
+}
+
+// ConfigFileNames 未指定 -config 时，在当前目录查找的配置文件
+var ConfigFileNames = []string{".gorecover.yaml", ".gorecover.yml", ".gorecover.json"}
+
+// LoadConfig 读取配置文件，支持 yaml 和 json 格式
+func LoadConfig(name string) (*Config, error) {
+	c := &Config{}
+	if err := decodeConfigFile(name, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// decodeConfigFile 读取 .gorecover.yaml 等配置文件，配置中有 Config 没有的字段(如拼写错误)时返回错误
+func decodeConfigFile(name string, c *Config) error {
+	bf, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(name) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(bf))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(c)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(bf))
+		dec.KnownFields(true)
+		err = dec.Decode(c)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parser %s failed: %w", name, err)
+	}
+	return nil
+}
+
+var (
+	configFile       string
+	safeFuncs        stringList
+	launchers        stringList
+	defaultLaunchers = true
+	reportFuncs      stringList
+	trustedModules   stringList
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&configFile, "config", "", "config file, default is "+strings.Join(ConfigFileNames, " or ")+" in current dir,\nor the go_recover section of "+strings.Join(zpass.ConfigFileNames, " or ")+" found from the package dir up")
+	Analyzer.Flags.Var(&safeFuncs, "safe-funcs", "comma-separated list of funcs considered recovered,\ne.g. github.com/sourcegraph/conc.(*WaitGroup).Go")
+	Analyzer.Flags.Var(&launchers, "launchers", "comma-separated list of funcs which launch goroutines,\ne.g. golang.org/x/sync/errgroup.(*Group).Go, time.AfterFunc#1")
+	Analyzer.Flags.BoolVar(&defaultLaunchers, "default-launchers", defaultLaunchers, "check well-known launchers: "+strings.Join(DefaultLaunchers, ", "))
+	Analyzer.Flags.Var(&reportFuncs, "report-funcs", "comma-separated list of funcs which report recovered panics, * is supported,\ne.g. github.com/my/metrics.*, built-in: "+strings.Join(DefaultReportFuncs, ", "))
+	Analyzer.Flags.Var(&trustedModules, "trusted-modules", "comma-separated list of modules whose funcs are considered recovered,\nprefix and * are supported, std is the standard library, e.g. std,golang.org/x")
+}
+
+// loadedConfig 一个配置文件合并命令行参数后的配置
+type loadedConfig struct {
+	config *Config
+	err    error
+}
+
+var (
+	// configs 配置文件路径 -> *loadedConfig，没有配置文件时为空字符串
+	configs sync.Map
+
+	legacyOnce sync.Once
+	legacyName string
+)
+
+// getConfig 返回 pass 所在 package 使用的配置，配置文件和命令行参数合并后的结果
+//
+// 配置文件的优先级：
+//   - -config 参数指定的文件
+//   - 当前目录下的 .gorecover.yaml 等文件
+//   - 从 package 所在目录向上查找的 .zpass.yaml 等文件中的 go_recover 配置
+func getConfig(pass *analysis.Pass) (*Config, error) {
+	name, legacy := configFile, true
+	if name == "" {
+		name = legacyConfigFile()
+	}
+	if name == "" {
+		name, legacy = zpass.FindConfigFile(zpass.PackageDir(pass)), false
+	}
+	if v, ok := configs.Load(name); ok {
+		lc := v.(*loadedConfig)
+		return lc.config, lc.err
+	}
+	c, err := loadConfig(pass.Analyzer, name, legacy)
+	v, _ := configs.LoadOrStore(name, &loadedConfig{config: c, err: err})
+	lc := v.(*loadedConfig)
+	return lc.config, lc.err
+}
+
+// legacyConfigFile 返回当前目录下的 .gorecover.yaml 等配置文件
+func legacyConfigFile() string {
+	legacyOnce.Do(func() {
+		for _, fn := range ConfigFileNames {
+			if _, err := os.Stat(fn); err == nil {
+				legacyName = fn
+				break
+			}
+		}
+	})
+	return legacyName
+}
+
+// loadConfig 读取配置文件 name 并和命令行参数合并，
+// legacy 为 true 时是 .gorecover.yaml 格式，否则为 .zpass.yaml 格式
+func loadConfig(a *analysis.Analyzer, name string, legacy bool) (*Config, error) {
+	c := &Config{}
+	if err := zpass.BindFlags(a, c, false); err != nil {
+		return nil, err
+	}
+	if name != "" {
+		var err error
+		if legacy {
+			err = decodeConfigFile(name, c)
+		} else {
+			err = zpass.DecodeConfig(name, a, c)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := zpass.BindFlags(a, c, true); err != nil {
+		return nil, err
+	}
+	c.SafeFuncs = append(c.SafeFuncs, safeFuncs...)
+	c.Launchers = append(c.Launchers, launchers...)
+	if defaultLaunchers && !c.NoDefaultLaunchers {
+		c.Launchers = append(c.Launchers, DefaultLaunchers...)
+	}
+	c.ReportFuncs = append(c.ReportFuncs, reportFuncs...)
+	c.ReportFuncs = append(c.ReportFuncs, DefaultReportFuncs...)
+	c.TrustedModules = append(c.TrustedModules, trustedModules...)
+	return c, nil
+}
+
+// IsSafeFunc 判断函数 fn 是否在可信的函数列表中
+func (c *Config) IsSafeFunc(fn *types.Func) bool {
+	return matchFunc(c.SafeFuncs, fn)
+}
+
+// LauncherArg 判断函数 fn 是否会启动 goroutine，
+// 返回值 index 是在 goroutine 中运行的参数序号，-1 表示所有 func 类型的参数
+func (c *Config) LauncherArg(fn *types.Func) (index int, ok bool) {
+	key := FuncKey(fn)
+	loose := looseFuncKey(key)
+	for _, name := range c.Launchers {
+		index = -1
+		if before, after, found := strings.Cut(name, "#"); found {
+			var err error
+			if index, err = strconv.Atoi(after); err != nil {
+				continue
+			}
+			name = before
+		}
+		if name == key || looseFuncKey(name) == loose {
+			return index, true
+		}
+	}
+	return -1, false
+}
+
+// IsReportFunc 判断函数 fn 是否是用于报告 panic 的函数
+func (c *Config) IsReportFunc(fn *types.Func) bool {
+	key := FuncKey(fn)
+	loose := looseFuncKey(key)
+	for _, pattern := range c.ReportFuncs {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+		if ok, _ := path.Match(looseFuncKey(pattern), loose); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTrustedModule 判断 module 是否可信，
+// 配置项可以是 module 路径，或者其前缀(如 golang.org/x)，也支持 * 通配符
+func (c *Config) IsTrustedModule(modPath string) bool {
+	for _, pattern := range c.TrustedModules {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if modPath == pattern || strings.HasPrefix(modPath, pattern+"/") {
+			return true
+		}
+		if ok, _ := path.Match(pattern, modPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchFunc(names []string, fn *types.Func) bool {
+	if len(names) == 0 {
+		return false
+	}
+	key := FuncKey(fn)
+	loose := looseFuncKey(key)
+	for _, name := range names {
+		if name == key || looseFuncKey(name) == loose {
+			return true
+		}
+	}
+	return false
+}
+
+// FuncKey 返回函数的全名，如：
+//
+//	github.com/fsgo/safe.Go
+//	github.com/sourcegraph/conc.(*WaitGroup).Go
+//	github.com/sourcegraph/conc.WaitGroup.Wait
+func FuncKey(fn *types.Func) string {
+	fn = fn.Origin()
+	if fn.Pkg() == nil {
+		return fn.Name()
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() == nil {
+		return fn.Pkg().Path() + "." + fn.Name()
+	}
+	rt := sig.Recv().Type()
+	var ptr bool
+	if pt, ok := rt.(*types.Pointer); ok {
+		ptr = true
+		rt = pt.Elem()
+	}
+	var tn string
+	if nt, ok := rt.(*types.Named); ok {
+		tn = nt.Obj().Name()
+	} else {
+		tn = types.TypeString(rt, func(*types.Package) string { return "" })
+	}
+	if ptr {
+		return fn.Pkg().Path() + ".(*" + tn + ")." + fn.Name()
+	}
+	return fn.Pkg().Path() + "." + tn + "." + fn.Name()
+}
+
+// looseFuncKey 去掉指针接收者的括号，
+// 使 conc.(*WaitGroup).Go 和 conc.WaitGroup.Go 可以互相匹配
+func looseFuncKey(key string) string {
+	return strings.NewReplacer("(*", "", ")", "").Replace(key)
+}
+
+// stringList 逗号分隔的字符串列表，可以多次指定
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set 添加逗号分隔的值，空的值(如配置中的空列表)表示没有值
+func (s *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*s = append(*s, v)
+		}
+	}
+	return nil
+}
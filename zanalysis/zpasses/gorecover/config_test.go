@@ -0,0 +1,78 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStringList(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   stringList
+	}{
+		{values: []string{"std"}, want: stringList{"std"}},
+		{values: []string{"std, golang.org/x ,"}, want: stringList{"std", "golang.org/x"}},
+		{values: []string{"a", "b,c"}, want: stringList{"a", "b", "c"}},
+		// 空列表，如配置中的 trusted-modules: []
+		{values: []string{""}, want: nil},
+		{values: []string{"a", ""}, want: stringList{"a"}},
+	}
+	for _, tt := range tests {
+		var got stringList
+		for _, v := range tt.values {
+			if err := got.Set(v); err != nil {
+				t.Fatalf("Set(%q): %v", v, err)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Set(%q) = %#v, want %#v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeConfigFile(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		want    Config
+		wantErr bool
+	}{
+		{
+			file:    ".gorecover.yaml",
+			content: "safe_funcs: [a.Go]\nmax_depth: 3\n",
+			want:    Config{SafeFuncs: []string{"a.Go"}, MaxDepth: 3},
+		},
+		{
+			file:    ".gorecover.json",
+			content: `{"safe_funcs": ["a.Go"], "max_depth": 3}`,
+			want:    Config{SafeFuncs: []string{"a.Go"}, MaxDepth: 3},
+		},
+		{file: ".gorecover.yaml"},
+		{file: ".gorecover.json"},
+		// 拼写错误的 key
+		{file: ".gorecover.yaml", content: "safe_func: [a.Go]\n", wantErr: true},
+		{file: ".gorecover.json", content: `{"safe_func": ["a.Go"]}`, wantErr: true},
+		{file: ".gorecover.json", content: `{"max_depth": "x"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		name := filepath.Join(t.TempDir(), tt.file)
+		if err := os.WriteFile(name, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var got Config
+		err := decodeConfigFile(name, &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %q: err = %v, wantErr %v", tt.file, tt.content, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %q: got %+v, want %+v", tt.file, tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// recover() 只有被 defer 的函数直接调用时才有效：
+//
+//	defer func() { recover() }()        // 有效
+//	defer recoverHelper()               // 有效，recoverHelper 内直接调用了 recover()
+//	defer recover()                     // 无效
+//	defer func() { recoverHelper() }()  // 无效，recover() 不是被 defer 的函数直接调用
+//	defer func() {
+//	    func() { recover() }()          // 无效，在嵌套的函数内
+//	}()
+
+// blockRecovered 判断函数体内是否有有效的 defer ... recover()
+// 返回 recover() 对应的节点及其位置
+func blockRecovered(pass *analysis.Pass, bs *ast.BlockStmt, resolve resolver) (ast.Node, string) {
+	// func body empty when with go:linkname
+	if bs == nil {
+		return nil, ""
+	}
+	for _, stmt := range bs.List {
+		ds, ok := stmt.(*ast.DeferStmt) // 是否包含defer 语句
+		if !ok {
+			continue
+		}
+		if fl, ok := astutil.Unparen(ds.Call.Fun).(*ast.FuncLit); ok {
+			// defer func(){ recover() }()
+			if calls := reachableRecoverCalls(pass, fl.Body); len(calls) > 0 {
+				return calls[0], pass.Fset.Position(calls[0].Pos()).String()
+			}
+			continue
+		}
+		// defer recoverHelper()
+		fn := typeutil.StaticCallee(pass.TypesInfo, ds.Call)
+		if fn == nil {
+			continue
+		}
+		if rf := resolve(fn); rf != nil && rf.DeferAt != "" {
+			return ds, rf.DeferAt
+		}
+	}
+	return nil, ""
+}
+
+// directRecoverAt 返回函数体内直接调用 recover() 的位置
+// 这样的函数被 defer 调用时，可以 recover
+func directRecoverAt(pass *analysis.Pass, body *ast.BlockStmt) string {
+	calls := reachableRecoverCalls(pass, body)
+	if len(calls) == 0 {
+		return ""
+	}
+	return pass.Fset.Position(calls[0].Pos()).String()
+}
+
+// reachableRecoverCalls 返回函数体内会被执行到的 recover() 调用
+// 不包括嵌套函数内的，以及 return 之后的
+func reachableRecoverCalls(pass *analysis.Pass, body *ast.BlockStmt) []*ast.CallExpr {
+	if body == nil {
+		return nil
+	}
+	var calls []*ast.CallExpr
+	var visitList func(list []ast.Stmt)
+	visit := func(node ast.Node) bool {
+		switch vt := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.BlockStmt:
+			visitList(vt.List)
+			return false
+		case *ast.CaseClause:
+			for _, expr := range vt.List {
+				calls = append(calls, recoverCalls(pass, expr)...)
+			}
+			visitList(vt.Body)
+			return false
+		case *ast.CommClause:
+			if vt.Comm != nil {
+				calls = append(calls, recoverCalls(pass, vt.Comm)...)
+			}
+			visitList(vt.Body)
+			return false
+		case *ast.CallExpr:
+			if isRecoverCall(pass, vt) {
+				calls = append(calls, vt)
+			}
+		}
+		return true
+	}
+	visitList = func(list []ast.Stmt) {
+		for _, stmt := range list {
+			ast.Inspect(stmt, visit)
+			if _, ok := stmt.(*ast.ReturnStmt); ok {
+				// 之后的语句不会被执行
+				return
+			}
+		}
+	}
+	visitList(body.List)
+	return calls
+}
+
+// recoverCalls 返回 node 内所有的 recover() 调用，不包括嵌套函数内的
+func recoverCalls(pass *analysis.Pass, node ast.Node) []*ast.CallExpr {
+	var calls []*ast.CallExpr
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch vt := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if isRecoverCall(pass, vt) {
+				calls = append(calls, vt)
+			}
+		}
+		return true
+	})
+	return calls
+}
+
+// isReachableRecover call 是否是函数体内会被执行到的 recover() 调用
+func isReachableRecover(pass *analysis.Pass, body *ast.BlockStmt, call *ast.CallExpr) bool {
+	for _, c := range reachableRecoverCalls(pass, body) {
+		if c == call {
+			return true
+		}
+	}
+	return false
+}
+
+func isRecoverCall(pass *analysis.Pass, call *ast.CallExpr) bool {
+	bt, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Builtin)
+	return ok && bt.Name() == "recover"
+}
+
+// checkRecoverCall 检查 recover() 的调用位置是否有效，call 是 stack 的最后一个元素
+func checkRecoverCall(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node) {
+	if !isRecoverCall(pass, call) {
+		checkIndirectRecover(pass, call, stack)
+		return
+	}
+	if ds, ok := stack[len(stack)-2].(*ast.DeferStmt); ok && ds.Call == call {
+		reportf(pass, RuleIneffective, call, "defer recover() has no effect, recover() must be called by a deferred func")
+		return
+	}
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch vt := stack[i].(type) {
+		case *ast.FuncDecl:
+			// 普通函数，可以被 defer 调用
+			if isReachableRecover(pass, vt.Body, call) {
+				checkSwallowed(pass, call, stack, vt.Body)
+			}
+			return
+		case *ast.FuncLit:
+			if isDeferredFuncLit(stack, i) {
+				if isReachableRecover(pass, vt.Body, call) {
+					checkSwallowed(pass, call, stack, vt.Body)
+					return
+				}
+				reportf(pass, RuleIneffective, call, "recover() after return is never called, has no effect")
+				return
+			}
+			if inDeferredFuncLit(stack, i) {
+				reportf(pass, RuleIneffective, call, "recover() called from nested func, has no effect")
+				return
+			}
+			if isCalledFuncLit(stack, i) {
+				// go func(){ recover() }()
+				reportf(pass, RuleIneffective, call, "recover() not called by a deferred func, has no effect")
+			}
+			// 其他情况，如赋值给变量的函数，可能会被 defer 调用
+			return
+		}
+	}
+}
+
+// checkIndirectRecover 检查 defer func(){ recoverHelper() }()，
+// recoverHelper 内的 recover() 不是被 defer 的函数直接调用的，无效
+func checkIndirectRecover(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node) {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil {
+		return
+	}
+	rf := importFact(pass, fn)
+	if rf == nil || rf.DeferAt == "" {
+		return
+	}
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.FuncDecl:
+			return
+		case *ast.FuncLit:
+			if isDeferredFuncLit(stack, i) {
+				reportf(pass, RuleIneffective, call, "recover() called indirectly via %s, has no effect", funcName(fn))
+			}
+			return
+		}
+	}
+}
+
+// checkDeferStmt 检查 defer recoverHelper()，recoverHelper 需要直接调用 recover()
+func checkDeferStmt(pass *analysis.Pass, ds *ast.DeferStmt, decls map[*types.Func]*ast.FuncDecl) {
+	fn := typeutil.StaticCallee(pass.TypesInfo, ds.Call)
+	if fn == nil {
+		return
+	}
+	fd, ok := decls[fn.Origin()]
+	if !ok || fd.Body == nil || directRecoverAt(pass, fd.Body) != "" {
+		return
+	}
+	if len(recoverCalls(pass, fd.Body)) > 0 {
+		reportf(pass, RuleIneffective, ds, "recover() in deferred func %s is never called, has no effect", funcName(fn))
+		return
+	}
+	if hasNestedRecover(pass, fd.Body) {
+		reportf(pass, RuleIneffective, ds, "recover() called from nested func in deferred func %s, has no effect", funcName(fn))
+		return
+	}
+	ast.Inspect(fd.Body, func(node ast.Node) bool {
+		switch vt := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			callee := typeutil.StaticCallee(pass.TypesInfo, vt)
+			if callee == nil {
+				return true
+			}
+			if rf := importFact(pass, callee); rf != nil && rf.DeferAt != "" {
+				reportf(pass, RuleIneffective, ds, "recover() called indirectly by deferred func %s via %s, has no effect", funcName(fn), funcName(callee))
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// hasNestedRecover 函数体内嵌套的函数中是否调用了 recover()
+func hasNestedRecover(pass *analysis.Pass, body *ast.BlockStmt) bool {
+	var found bool
+	ast.Inspect(body, func(node ast.Node) bool {
+		if fl, ok := node.(*ast.FuncLit); ok && !found {
+			found = len(recoverCalls(pass, fl.Body)) > 0 || hasNestedRecover(pass, fl.Body)
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// isDeferredFuncLit 判断 stack[i] 是否是 defer func(){}() 中的函数，函数可以带括号，如 defer (func(){})()
+func isDeferredFuncLit(stack []ast.Node, i int) bool {
+	j := calledAt(stack, i)
+	if j < 1 {
+		return false
+	}
+	ds, ok := stack[j-1].(*ast.DeferStmt)
+	return ok && ds.Call == stack[j]
+}
+
+// isCalledFuncLit 判断 stack[i] 是否是直接被调用的函数，如 func(){}()
+func isCalledFuncLit(stack []ast.Node, i int) bool {
+	return calledAt(stack, i) >= 0
+}
+
+// calledAt 返回直接调用 stack[i] 的 CallExpr 在 stack 中的下标，跳过 stack[i] 外的括号，
+// 不是被直接调用时返回 -1
+func calledAt(stack []ast.Node, i int) int {
+	j := i - 1
+	for j >= 0 {
+		if _, ok := stack[j].(*ast.ParenExpr); !ok {
+			break
+		}
+		j--
+	}
+	if j < 0 {
+		return -1
+	}
+	ce, ok := stack[j].(*ast.CallExpr)
+	if !ok || astutil.Unparen(ce.Fun) != stack[i] {
+		return -1
+	}
+	return j
+}
+
+// inDeferredFuncLit 判断 stack[i] 是否在 defer func(){}() 内
+func inDeferredFuncLit(stack []ast.Node, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		switch stack[j].(type) {
+		case *ast.FuncDecl:
+			return false
+		case *ast.FuncLit:
+			if isDeferredFuncLit(stack, j) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// RecoversFact 导出在函数上的 fact，用于跨 package 判断函数是否已 recover
+type RecoversFact struct {
+	// At 函数体内 defer ... recover() 的位置，为空表示函数本身未 recover
+	At string
+
+	// Chain 从当前函数到 recover() 所在函数的调用链，不包含当前函数
+	// 为空表示 recover() 就在当前函数内
+	Chain []string
+
+	// DeferAt 函数体内直接调用 recover() 的位置，
+	// 这样的函数可以用于 defer recoverHelper()
+	DeferAt string
+
+	// Returns 函数返回的函数都已 recover，如：
+	// func factory() func() { return func(){ defer func(){ recover() }() } }
+	Returns bool
+}
+
+func (*RecoversFact) AFact() {}
+
+func (f *RecoversFact) String() string {
+	if f.At == "" {
+		if f.DeferAt != "" {
+			return "calls recover at " + f.DeferAt
+		}
+		return "returns recovered"
+	}
+	if len(f.Chain) > 0 {
+		return "recovers via " + chainString(f.Chain) + " at " + f.At
+	}
+	return "recovers at " + f.At
+}
+
+// Recovered 函数本身是否已 recover
+func (f *RecoversFact) Recovered() bool {
+	return f.At != ""
+}
+
+// importFact 读取函数 fn 上的 RecoversFact，包括当前 package 和依赖的 package
+// 配置为可信的函数、可信的 module 中的函数，认为其已 recover
+func importFact(pass *analysis.Pass, fn *types.Func) *RecoversFact {
+	if cfg, _ := getConfig(pass); cfg != nil {
+		if cfg.IsSafeFunc(fn) {
+			return &RecoversFact{At: "safe func " + FuncKey(fn)}
+		}
+		if mf := externalModule(pass, fn); mf != nil && cfg.IsTrustedModule(mf.Path) {
+			return &RecoversFact{At: "trusted module " + mf.Path}
+		}
+	}
+	rf := &RecoversFact{}
+	if !pass.ImportObjectFact(fn.Origin(), rf) {
+		return nil
+	}
+	return rf
+}
+
+// exportFacts 给当前 package 中所有已 recover 的函数导出 RecoversFact
+// 依赖的 package 会先于当前 package 分析，所以其 fact 可以直接读取
+// 返回当前 package 中所有的函数定义
+func exportFacts(pass *analysis.Pass) map[*types.Func]*ast.FuncDecl {
+	fe := &factExporter{
+		pass:  pass,
+		decls: make(map[*types.Func]*ast.FuncDecl),
+		facts: make(map[*types.Func]*RecoversFact),
+	}
+	var fns []*types.Func
+	for _, f := range pass.Files {
+		for _, d := range f.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func); ok {
+				fe.decls[fn] = fd
+				fns = append(fns, fn)
+			}
+		}
+	}
+	for _, fn := range fns {
+		if rf := fe.funcFact(fn); rf != nil {
+			pass.ExportObjectFact(fn, rf)
+		}
+	}
+
+	// 函数返回的 func 字面量可能调用当前 package 的其他函数，
+	// 所以需要在上面的 fact 都导出后再判断
+	for _, fn := range fns {
+		if !fe.returnsRecovered(fn, fe.decls[fn]) {
+			continue
+		}
+		rf := importFact(pass, fn)
+		if rf == nil {
+			rf = &RecoversFact{}
+		}
+		rf.Returns = true
+		pass.ExportObjectFact(fn, rf)
+	}
+	return fe.decls
+}
+
+type factExporter struct {
+	pass  *analysis.Pass
+	decls map[*types.Func]*ast.FuncDecl
+	facts map[*types.Func]*RecoversFact
+}
+
+// funcFact 返回函数 fn 的 RecoversFact，未 recover 且未直接调用 recover() 时返回 nil
+// 当前 package 的函数，会递归判断其调用的函数
+func (fe *factExporter) funcFact(fn *types.Func) *RecoversFact {
+	fn = fn.Origin()
+	if rf := importFact(fe.pass, fn); rf != nil {
+		return rf
+	}
+	fd, ok := fe.decls[fn]
+	if !ok {
+		return nil
+	}
+	if rf, ok := fe.facts[fn]; ok {
+		return rf
+	}
+
+	rf := &RecoversFact{
+		DeferAt: directRecoverAt(fe.pass, fd.Body),
+	}
+	// 先记录，递归调用时使用
+	fe.facts[fn] = rf
+	if r, _, _ := bodyRecovered(fe.pass, fd.Body, fe.funcFact); r != nil {
+		rf.At = r.At
+		rf.Chain = r.Chain
+	}
+	if rf.At == "" && rf.DeferAt == "" {
+		fe.facts[fn] = nil
+		return nil
+	}
+	return rf
+}
+
+// returnsRecovered 判断函数返回的函数是否都已 recover，支持 func 字面量、函数以及赋值为这些值的局部变量，
+// 返回其他的值(如参数、函数调用的结果)时，静态无法确定，认为未 recover
+func (fe *factExporter) returnsRecovered(fn *types.Func, fd *ast.FuncDecl) bool {
+	if fd.Body == nil || !hasFuncResult(fn) {
+		return false
+	}
+	pass := fe.pass
+	ok := true
+	ast.Inspect(fd.Body, func(node ast.Node) bool {
+		switch vt := node.(type) {
+		case *ast.FuncLit:
+			// 不检查内部函数的 return
+			return false
+		case *ast.ReturnStmt:
+			for _, expr := range vt.Results {
+				tp := pass.TypesInfo.TypeOf(expr)
+				if _, isTuple := tp.(*types.Tuple); isTuple {
+					// return factory()，返回多个值
+					ok = false
+					continue
+				}
+				if tp == nil || !isFuncType(tp) {
+					// 非函数类型的返回值，以及 nil
+					continue
+				}
+				values := []ast.Expr{expr}
+				if id, isIdent := astutil.Unparen(expr).(*ast.Ident); isIdent {
+					if v, isVar := pass.TypesInfo.Uses[id].(*types.Var); isVar {
+						if vs, known := localFuncValues(pass, v); known {
+							values = vs
+						}
+					}
+				}
+				if recovered, _, _ := funcValuesRecovered(pass, values, &recovery{}); !recovered {
+					ok = false
+				}
+			}
+		}
+		return true
+	})
+	return ok
+}
+
+func hasFuncResult(fn *types.Func) bool {
+	results := fn.Type().(*types.Signature).Results()
+	for i := 0; i < results.Len(); i++ {
+		if isFuncType(results.At(i).Type()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,429 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/fsgo/gocode/internal/asthelper"
+)
+
+var (
+	fixHandler = "log.Println"
+	fixImport  = "log"
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&fixHandler, "fix-handler", fixHandler, "func called with the recovered value in suggested fixes, e.g. mylog.Panic")
+	Analyzer.Flags.StringVar(&fixImport, "fix-import", fixImport, "import path needed by -fix-handler, empty for none")
+}
+
+// suggestFixes 为未 recover 的 goroutine 生成修复：
+//
+//	go func(){ ... }()   =>  在函数体开头插入 defer func(){ if r := recover(); r != nil { log.Println(r) } }()
+//	go fn(a, b)          =>  go func(p0 A, p1 B) { defer ...; fn(p0, p1) }(a, b)
+//	eg.Go(task)          =>  eg.Go(func() (err error) { defer ...; return task() })
+//
+// 参数和函数值(如 s.Run、factory())依旧在启动 goroutine 前求值，和原来一致，
+// 函数的最后一个返回值是 error 时(如 errgroup 的任务)，recover 到的值作为 error 返回，而不是返回 nil
+func (c *checker) suggestFixes(node ast.Node, fun ast.Expr) []analysis.SuggestedFix {
+	pass := c.pass
+	file := fileOf(pass, node.Pos())
+	if file == nil || fixHandler == "" {
+		return nil
+	}
+	indent := c.lineIndent(node.Pos())
+	var edits []analysis.TextEdit
+	var errName string
+	if fl, ok := astutil.Unparen(fun).(*ast.FuncLit); ok {
+		edits, errName = funcLitEdits(pass, fl, indent)
+	} else if gs, ok := node.(*ast.GoStmt); ok {
+		edits = goCallEdits(pass, file, gs.Call, indent)
+	} else if expr, ok := node.(ast.Expr); ok {
+		edits, errName = funcValueEdits(pass, file, expr, indent)
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+	imp := fixImport
+	if errName != "" {
+		imp = "fmt"
+	}
+	if edit, ok := importEdit(file, imp); ok {
+		edits = append(edits, edit)
+	}
+	return []analysis.SuggestedFix{
+		{
+			Message:   "add defer recover()",
+			TextEdits: edits,
+		},
+	}
+}
+
+// deferCode 生成 defer recover 的代码，每行以 indent 开头，以换行结尾，
+// errName 不为空时 recover 到的值作为 error 赋值给返回值 errName，否则交给 -fix-handler 处理
+func deferCode(indent string, errName string) string {
+	handle := fixHandler + "(r)"
+	if errName != "" {
+		handle = errName + ` = fmt.Errorf("panic: %v", r)`
+	}
+	var bf strings.Builder
+	bf.WriteString(indent + "defer func() {\n")
+	bf.WriteString(indent + "\tif r := recover(); r != nil {\n")
+	bf.WriteString(indent + "\t\t" + handle + "\n")
+	bf.WriteString(indent + "\t}\n")
+	bf.WriteString(indent + "}()\n")
+	return bf.String()
+}
+
+// funcLitEdits 在 func(){} 函数体的开头插入 defer recover，
+// 最后一个返回值是 error 时，返回值没有名字的会加上名字，返回用于 error 的返回值的名字
+func funcLitEdits(pass *analysis.Pass, fl *ast.FuncLit, indent string) (edits []analysis.TextEdit, errName string) {
+	if sig, ok := pass.TypesInfo.TypeOf(fl).(*types.Signature); ok && lastIsError(sig) {
+		var edit *analysis.TextEdit
+		if errName, edit = namedResults(pass, fl); edit != nil {
+			edits = append(edits, *edit)
+		}
+	}
+	body := fl.Body
+	tf := pass.Fset.File(body.Lbrace)
+	if line := tf.Line(body.Lbrace); line != tf.Line(body.Rbrace) {
+		if len(body.List) == 0 || tf.Line(body.List[0].Pos()) > line {
+			// 插入到下一行的开头，"{" 后的注释留在原处
+			pos := tf.LineStart(line + 1)
+			return append(edits, analysis.TextEdit{
+				Pos:     pos,
+				End:     pos,
+				NewText: []byte(deferCode(indent+"\t", errName)),
+			}), errName
+		}
+		return append(edits, analysis.TextEdit{
+			Pos:     body.Lbrace + 1,
+			End:     body.Lbrace + 1,
+			NewText: []byte("\n" + strings.TrimSuffix(deferCode(indent+"\t", errName), "\n")),
+		}), errName
+	}
+	// 函数体只有一行，如 go func() { work() }()，整个重写
+	var bf strings.Builder
+	bf.WriteString("{\n")
+	bf.WriteString(deferCode(indent+"\t", errName))
+	for _, stmt := range body.List {
+		bf.WriteString(indent + "\t" + nodeString(pass.Fset, stmt) + "\n")
+	}
+	bf.WriteString(indent + "}")
+	return append(edits, analysis.TextEdit{
+		Pos:     body.Lbrace,
+		End:     body.Rbrace + 1,
+		NewText: []byte(bf.String()),
+	}), errName
+}
+
+// namedResults 返回 fl 最后一个返回值(error)的名字，没有名字时，返回给所有返回值加上名字的修改：
+//
+//	func() error        =>  func() (err error)
+//	func() (int, error) =>  func() (_ int, err error)
+//
+// 返回值的名字为 "_" 时无法赋值，返回空
+func namedResults(pass *analysis.Pass, fl *ast.FuncLit) (string, *analysis.TextEdit) {
+	results := fl.Type.Results
+	last := results.List[len(results.List)-1]
+	if len(last.Names) > 0 {
+		if name := last.Names[len(last.Names)-1].Name; name != "_" {
+			return name, nil
+		}
+		return "", nil
+	}
+	name := unusedName(fl.Body, "err")
+	var fields []string
+	for _, field := range results.List {
+		fields = append(fields, "_ "+nodeString(pass.Fset, field.Type))
+	}
+	fields[len(fields)-1] = name + " " + nodeString(pass.Fset, last.Type)
+	return name, &analysis.TextEdit{
+		Pos:     results.Pos(),
+		End:     results.End(),
+		NewText: []byte("(" + strings.Join(fields, ", ") + ")"),
+	}
+}
+
+// unusedName 返回 node 中没有使用的名字，如 err、err1，避免遮蔽函数中使用的外部变量
+func unusedName(node ast.Node, name string) string {
+	used := make(map[string]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			used[id.Name] = true
+		}
+		return true
+	})
+	for i := 1; used[name]; i++ {
+		name = strings.TrimRight(name, "0123456789") + strconv.Itoa(i)
+	}
+	return name
+}
+
+// lastIsError 函数的最后一个返回值是否是 error
+func lastIsError(sig *types.Signature) bool {
+	n := sig.Results().Len()
+	return n > 0 && types.Identical(sig.Results().At(n-1).Type(), types.Universe.Lookup("error").Type())
+}
+
+// goCallEdits 将 go fn(a, b) 改写为 go func(p0 A, p1 B) { defer ...; fn(p0, p1) }(a, b)
+func goCallEdits(pass *analysis.Pass, file *ast.File, call *ast.CallExpr, indent string) []analysis.TextEdit {
+	sig, ok := pass.TypesInfo.TypeOf(call.Fun).Underlying().(*types.Signature)
+	if !ok {
+		return nil
+	}
+	qf := fileQualifier(pass, file)
+	var params, callArgs, args []string
+	callee := nodeString(pass.Fset, call.Fun)
+	if !isPlainFunc(pass, call.Fun) {
+		// s.Run、factory() 等需要先求值
+		tp, ok := typeString(sig, qf)
+		if !ok {
+			return nil
+		}
+		params = append(params, "fn "+tp)
+		args = append(args, callee)
+		callee = "fn"
+	}
+	for i, arg := range call.Args {
+		pt, ok := paramType(sig, i, call.Ellipsis.IsValid())
+		if !ok {
+			return nil
+		}
+		tp, ok := typeString(pt, qf)
+		if !ok {
+			return nil
+		}
+		name := "p" + strconv.Itoa(i)
+		params = append(params, name+" "+tp)
+		args = append(args, nodeString(pass.Fset, arg))
+		callArgs = append(callArgs, name)
+	}
+	var ellipsis string
+	if call.Ellipsis.IsValid() {
+		ellipsis = "..."
+	}
+	var bf strings.Builder
+	bf.WriteString("func(" + strings.Join(params, ", ") + ") {\n")
+	bf.WriteString(deferCode(indent+"\t", ""))
+	bf.WriteString(indent + "\t" + callee + "(" + strings.Join(callArgs, ", ") + ellipsis + ")\n")
+	bf.WriteString(indent + "}(" + strings.Join(args, ", ") + ")")
+	return []analysis.TextEdit{
+		{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			NewText: []byte(bf.String()),
+		},
+	}
+}
+
+// funcValueEdits 将 launcher 参数中的函数 task 改写为 func() (err error) { defer ...; return task() }
+// 只处理包级别的函数，变量等在运行时才求值，改写后行为可能不同，
+// 返回用于 error 的返回值的名字，见 suggestFixes
+func funcValueEdits(pass *analysis.Pass, file *ast.File, expr ast.Expr, indent string) ([]analysis.TextEdit, string) {
+	if !isPlainFunc(pass, expr) {
+		return nil, ""
+	}
+	sig, ok := pass.TypesInfo.TypeOf(expr).Underlying().(*types.Signature)
+	if !ok {
+		return nil, ""
+	}
+	qf := fileQualifier(pass, file)
+	var params, callArgs, results []string
+	for i := 0; i < sig.Params().Len(); i++ {
+		tp, ok := typeString(sig.Params().At(i).Type(), qf)
+		if !ok {
+			return nil, ""
+		}
+		name := "p" + strconv.Itoa(i)
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			params = append(params, name+" ..."+strings.TrimPrefix(tp, "[]"))
+			callArgs = append(callArgs, name+"...")
+			continue
+		}
+		params = append(params, name+" "+tp)
+		callArgs = append(callArgs, name)
+	}
+	var errName string
+	if lastIsError(sig) {
+		errName = unusedName(expr, "err")
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		tp, ok := typeString(sig.Results().At(i).Type(), qf)
+		if !ok {
+			return nil, ""
+		}
+		switch {
+		case errName == "":
+		case i == sig.Results().Len()-1:
+			tp = errName + " " + tp
+		default:
+			tp = "_ " + tp
+		}
+		results = append(results, tp)
+	}
+	var result string
+	switch {
+	case len(results) == 0:
+	case len(results) == 1 && errName == "":
+		result = " " + results[0]
+	default:
+		result = " (" + strings.Join(results, ", ") + ")"
+	}
+	var ret string
+	if len(results) > 0 {
+		ret = "return "
+	}
+	var bf strings.Builder
+	bf.WriteString("func(" + strings.Join(params, ", ") + ")" + result + " {\n")
+	bf.WriteString(deferCode(indent+"\t", errName))
+	bf.WriteString(indent + "\t" + ret + nodeString(pass.Fset, expr) + "(" + strings.Join(callArgs, ", ") + ")\n")
+	bf.WriteString(indent + "}")
+	return []analysis.TextEdit{
+		{
+			Pos:     expr.Pos(),
+			End:     expr.End(),
+			NewText: []byte(bf.String()),
+		},
+	}, errName
+}
+
+// isPlainFunc 是否是包级别的函数，如 fn、pkg.Fn、run[int]，求值时机不影响结果
+func isPlainFunc(pass *analysis.Pass, fun ast.Expr) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, &ast.CallExpr{Fun: fun}).(*types.Func)
+	return ok && fn.Type().(*types.Signature).Recv() == nil
+}
+
+// paramType 返回第 i 个实参对应的形参类型
+func paramType(sig *types.Signature, i int, hasEllipsis bool) (types.Type, bool) {
+	n := sig.Params().Len()
+	if !sig.Variadic() || i < n-1 {
+		if i >= n {
+			return nil, false
+		}
+		return sig.Params().At(i).Type(), true
+	}
+	last := sig.Params().At(n - 1).Type()
+	if hasEllipsis {
+		return last, true
+	}
+	st, ok := last.Underlying().(*types.Slice)
+	if !ok {
+		return nil, false
+	}
+	return st.Elem(), true
+}
+
+// fileQualifier 使用文件中 import 的包名，未 import 的包使用 \x00 标记，见 typeString
+func fileQualifier(pass *analysis.Pass, file *ast.File) types.Qualifier {
+	names := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			names[path] = spec.Name.Name
+			continue
+		}
+		if pkgName, ok := pass.TypesInfo.Implicits[spec].(*types.PkgName); ok {
+			names[path] = pkgName.Imported().Name()
+		}
+	}
+	return func(p *types.Package) string {
+		if p == pass.Pkg {
+			return ""
+		}
+		if name, ok := names[p.Path()]; ok && name != "_" && name != "." {
+			return name
+		}
+		return "\x00" + p.Path()
+	}
+}
+
+// typeString 返回类型在当前文件中的写法，类型所在的包未 import 时返回 false
+func typeString(t types.Type, qf types.Qualifier) (string, bool) {
+	str := types.TypeString(t, qf)
+	return str, !strings.Contains(str, "\x00")
+}
+
+// importEdit 文件中没有 import path 时，返回添加 import 的修改
+func importEdit(file *ast.File, path string) (analysis.TextEdit, bool) {
+	if path == "" || asthelper.HasImport(file, path) {
+		return analysis.TextEdit{}, false
+	}
+	quoted := strconv.Quote(path)
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		if gd.Lparen.IsValid() {
+			return analysis.TextEdit{
+				Pos:     gd.Lparen + 1,
+				End:     gd.Lparen + 1,
+				NewText: []byte("\n\t" + quoted),
+			}, true
+		}
+		return analysis.TextEdit{
+			Pos:     gd.Pos(),
+			End:     gd.Pos(),
+			NewText: []byte("import " + quoted + "\n"),
+		}, true
+	}
+	return analysis.TextEdit{
+		Pos:     file.Name.End(),
+		End:     file.Name.End(),
+		NewText: []byte("\n\nimport " + quoted),
+	}, true
+}
+
+// lineIndent 返回 pos 所在行开头的空白，读取的文件内容会缓存到 c.sources 中
+func (c *checker) lineIndent(pos token.Pos) string {
+	tf := c.pass.Fset.File(pos)
+	if tf == nil {
+		return ""
+	}
+	content, ok := c.sources[tf]
+	if !ok {
+		readFile := os.ReadFile
+		if c.pass.ReadFile != nil {
+			readFile = c.pass.ReadFile
+		}
+		content, _ = readFile(tf.Name())
+		if c.sources == nil {
+			c.sources = make(map[*token.File][]byte)
+		}
+		c.sources[tf] = content
+	}
+	if content == nil {
+		// 按照 gofmt 后的代码，使用 tab 缩进
+		return strings.Repeat("\t", max(tf.Position(pos).Column-1, 0))
+	}
+	start := tf.Offset(tf.LineStart(tf.Line(pos)))
+	end := start
+	for end < len(content) && (content[end] == ' ' || content[end] == '\t') {
+		end++
+	}
+	return string(content[start:end])
+}
+
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	var bf bytes.Buffer
+	if err := format.Node(&bf, fset, node); err != nil {
+		return fmt.Sprint(node)
+	}
+	return bf.String()
+}
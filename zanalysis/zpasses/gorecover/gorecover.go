@@ -5,18 +5,20 @@
 package gorecover
 
 import (
-	"errors"
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"log"
+	"reflect"
 	"runtime"
-	"sync"
 
 	"github.com/fatih/color"
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
 
 	"github.com/fsgo/gocode/internal/asthelper"
 	"github.com/fsgo/gocode/zpass"
@@ -26,52 +28,149 @@ const Doc = `find goroutines not recovered
 with flag "-debug v" for verbose
 `
 
-var container = &zpass.Container{}
-
 var Analyzer = &analysis.Analyzer{
 	Name: "zpass_go_recover",
 	Doc:  Doc,
 	Requires: []*analysis.Analyzer{
 		inspect.Analyzer,
-		zpass.NewInitAnalyzer(container),
 	},
-	Run: run,
+	Run:        run,
+	FactTypes:  []analysis.Fact{new(RecoversFact), new(ModuleFact)},
+	ResultType: reflect.TypeOf(new(Result)),
+}
+
+// container 和其他 analyzer 共享，测试代码的检查方式 Tests 由 -tests 参数指定
+var container = zpass.DefaultContainer
+
+// skipTestingImport 是否跳过 import 了 "testing" 的非测试文件，如测试用的辅助函数
+var skipTestingImport = true
+
+func init() {
+	zpass.Register(Analyzer)
+	zpass.OnFinish(Analyzer, saveBaseline)
+	zpass.SetCacheOptions(Analyzer, zpass.CacheOptions{
+		Version:  "1",
+		Files:    cacheFiles,
+		Disabled: func() bool { return baselineUpdate },
+	})
+	Analyzer.Flags.IntVar(&maxDepth, "max-depth", maxDepth, "max depth of wrapper func chain to follow")
+	Analyzer.Flags.Var(&container.Tests, "tests", "how to check test code (_test.go files and xxx_test packages): skip|warn|report\nwarn reports findings as warnings, which don't affect the exit code\nit's applied to loaded test files, -test=false doesn't load them at all")
+	Analyzer.Flags.BoolVar(&skipTestingImport, "skip-testing-import", skipTestingImport, `skip non-test files which import "testing", e.g. test helpers`)
+}
+
+// cacheFiles 影响检查结果的配置文件和基线文件，用于计算缓存的 key
+func cacheFiles(pass *analysis.Pass) []string {
+	return []string{configFile, legacyConfigFile(), baselineFile}
+}
+
+// checker 一个 pass 的检查状态，每个 package 单独创建，可以并行执行
+type checker struct {
+	pass   *analysis.Pass
+	cfg    *Config
+	decls  map[*types.Func]*ast.FuncDecl
+	result *Result
+	ig     *ignorer
+
+	// checked 已检查的文件，避免重复检查
+	checked map[string]bool
+
+	// testFiles 测试代码文件：_test.go 文件，以及 import 了 "testing" 的文件
+	testFiles map[string]bool
+
+	// ssa -mode=ssa 时使用，见 ssaChecker()
+	ssa *ssaChecker
+
+	// sources 生成修复时读取的源文件内容，见 lineIndent
+	sources map[*token.File][]byte
 }
 
 func run(pass *analysis.Pass) (any, error) {
-	if zpass.IsTestPkg(pass.Pkg.Path()) {
-		return nil, nil
+	zpass.TryParseFlags()
+	cfg, err := getConfig(pass)
+	if err != nil {
+		return nil, err
+	}
+	exportModuleFact(pass)
+	c := &checker{
+		pass:      pass,
+		cfg:       cfg,
+		decls:     exportFacts(pass),
+		result:    &Result{Package: pass.Pkg.Path()},
+		ig:        &ignorer{},
+		checked:   make(map[string]bool),
+		testFiles: make(map[string]bool),
+	}
+
+	// go test 生成的 main package 不需要检查
+	if zpass.IsTestMainPkg(pass.Pkg.Path()) || (zpass.IsTestPkg(pass.Pkg.Path()) && container.Tests.Skip()) {
+		return c.result, nil
 	}
 
 	if zpass.IsTrace() {
 		log.Printf("[%s] start check pkg: %s: %s\n", pass.Analyzer.Name, pass.Pkg.Name(), pass.Pkg.Path())
 	}
-	container.SetCurrentPass(pass)
+
+	var inBaseline func(f Finding) bool
+	if baselineFile != "" && !zpass.VetxOnly() {
+		if inBaseline, err = baseline.filter(pass.Pkg); err != nil {
+			return nil, err
+		}
+		if baselineUpdate && !zpass.Analyzing() {
+			// go vet 等 driver 没有分析完所有 package 的时机，每个 package 合并写入一次
+			defer func() {
+				if err1 := baseline.save(); err1 != nil {
+					log.Println("write baseline failed:", err1)
+				}
+			}()
+		}
+	}
+	report := pass.Report
+	defer func() {
+		pass.Report = report
+	}()
+	pass.Report = func(d analysis.Diagnostic) {
+		if c.ig.ignored(d.Pos) {
+			return
+		}
+		// 依赖的 package 也会被分析(用于导出 fact)，只记录当前目录下的
+		if inBaseline != nil && isLocalFile(pass.Fset.File(d.Pos)) && inBaseline(newFinding(pass, d)) {
+			return
+		}
+		if container.Tests == zpass.TestWarn && c.isTestFile(d.Pos) && zpass.SeverityOf(d.Category) == zpass.SeverityError {
+			d.Category = zpass.WithSeverity(d.Category, zpass.SeverityWarning)
+			d.Message = warningPrefix + d.Message
+		}
+		report(d)
+	}
 
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	nodeFilter := []ast.Node{
 		(*ast.File)(nil),
 		(*ast.GoStmt)(nil),
+		(*ast.DeferStmt)(nil),
+		(*ast.CallExpr)(nil),
 	}
-	var ignore bool
-	inspect.Preorder(nodeFilter, func(node ast.Node) {
-		if ignore {
-			return
+	inspect.WithStack(nodeFilter, func(node ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
 		}
-
-		if nf, ok := node.(*ast.File); ok {
-			ignore = checkIgnore(pass, nf)
-			if ignore {
-				return
+		switch vt := node.(type) {
+		case *ast.File:
+			if c.checkIgnore(vt) {
+				return false
 			}
+			c.ig.addFile(pass, vt)
+		case *ast.GoStmt:
+			c.check(vt)
+		case *ast.DeferStmt:
+			checkDeferStmt(pass, vt, c.decls)
+		case *ast.CallExpr:
+			checkRecoverCall(pass, vt, stack)
+			c.checkLaunch(vt)
 		}
-		gs, ok := node.(*ast.GoStmt)
-		if !ok {
-			return
-		}
-		check(pass, gs)
+		return true
 	})
-	return nil, nil
+	return c.result, nil
 }
 
 func countGoStmt(f *ast.File) int {
@@ -81,268 +180,366 @@ func countGoStmt(f *ast.File) int {
 	})
 }
 
-var checked sync.Map
-
-func checkIgnore(pass *analysis.Pass, nf *ast.File) bool {
+// checkIgnore 是否不检查文件 nf，
+// 生成的代码、-exclude 等通用的过滤由 zpass.ApplyFilter 处理，这里只处理测试代码和忽略指令
+func (c *checker) checkIgnore(nf *ast.File) bool {
+	pass := c.pass
 	tokenFile := pass.Fset.File(nf.Pos())
 
-	if !asthelper.IsGoFile(tokenFile) {
-		return true
-	}
-
-	if asthelper.IsGoTestFile(tokenFile) {
+	isTest := asthelper.IsGoTestFile(tokenFile)
+	if isTest && container.Tests.Skip() {
 		return true
 	}
 
 	rn := asthelper.RelName(tokenFile.Name())
 
-	if asthelper.HasImport(nf, "testing") {
+	if hasFileIgnore(nf) {
 		if zpass.IsDebugVerbose() {
-			log.Println(`ignored: has import "testing":`, rn, ", has GoStmt:", countGoStmt(nf))
+			log.Println("ignored: has "+fileIgnoreDirective+":", rn)
 		}
 		return true
 	}
 
-	if _, ok := checked.Load(tokenFile.Name()); ok {
+	if !isTest && asthelper.HasImport(nf, "testing") {
+		if skipTestingImport {
+			if zpass.IsDebugVerbose() {
+				log.Println(`ignored: has import "testing":`, rn, ", has GoStmt:", countGoStmt(nf))
+			}
+			return true
+		}
+		// 测试用的辅助函数，-tests=warn 时作为警告
+		isTest = true
+	}
+
+	if c.checked[tokenFile.Name()] {
 		return true
 	}
-	checked.Store(tokenFile.Name(), true)
+	c.checked[tokenFile.Name()] = true
+	if isTest {
+		c.testFiles[tokenFile.Name()] = true
+	}
 
 	return false
 }
 
-var successID int
-var failID int
+// warningPrefix -tests=warn 时，测试代码中问题的前缀
+const warningPrefix = "[warning] "
+
+// isTestFile pos 是否在测试代码文件中
+func (c *checker) isTestFile(pos token.Pos) bool {
+	tf := c.pass.Fset.File(pos)
+	return tf != nil && c.testFiles[tf.Name()]
+}
+
+func (c *checker) check(gs *ast.GoStmt) (ok bool) {
+	return c.checkFunc(gs, gs.Call.Fun, "")
+}
 
-func check(pass *analysis.Pass, gs *ast.GoStmt) (ok bool) {
-	// 默认就是自己
-	// 为了兼容 go panic() 等不需要 recover 的场景
-	recoverAt = gs
+// checkLaunch 检查 launcher 函数参数中的函数，如 eg.Go(fn)
+func (c *checker) checkLaunch(call *ast.CallExpr) {
+	pass := c.pass
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil || c.cfg.IsSafeFunc(fn) {
+		return
+	}
+	index, ok := c.cfg.LauncherArg(fn)
+	if !ok {
+		return
+	}
+	for i, arg := range call.Args {
+		if index >= 0 && i != index {
+			continue
+		}
+		if tv, ok := pass.TypesInfo.Types[arg]; ok && !tv.IsNil() && isFuncType(tv.Type) {
+			c.checkFunc(arg, arg, FuncKey(fn))
+		}
+	}
+}
 
-	code1 := asthelper.NodeCode(pass, gs, 10)
+// recovery 检查一个 goroutine 时，找到的 recover()
+type recovery struct {
+	// at recover() 或者已 recover 函数的调用所在的节点，用于输出代码
+	at ast.Node
+
+	// rf recover() 的位置和调用链
+	rf *RecoversFact
+
+	// skip 不需要 recover 或者静态无法确定时，跳过的原因
+	skip string
+
+	// dynamic 是否因为运行的函数静态无法确定而跳过，这样的 goroutine 以 info 级别报告
+	dynamic bool
+}
+
+// skipDynamic 运行的函数静态无法确定(如参数、接口方法)，跳过检查
+func (r *recovery) skipDynamic(reason string) {
+	r.skip = reason
+	r.dynamic = true
+}
+
+func (r *recovery) set(rf *RecoversFact, at ast.Node) {
+	r.rf = rf
+	r.at = at
+}
+
+// goroutine 返回检查结果，ok 为 false 时 reason 为未 recover 的原因
+func (r *recovery) goroutine(pass *analysis.Pass, node ast.Node, launcher string, ok bool, reason string) *Goroutine {
+	g := &Goroutine{
+		Pos:      pass.Fset.Position(node.Pos()),
+		Launcher: launcher,
+	}
+	switch {
+	case !ok:
+		g.Status = StatusUnrecovered
+		g.Reason = reason
+	case r.skip != "":
+		g.Status = StatusSkipped
+		g.Reason = r.skip
+	default:
+		g.Status = StatusRecovered
+		if r.rf != nil {
+			g.RecoverAt = r.rf.At
+			g.Chain = r.rf.Chain
+		}
+	}
+	return g
+}
+
+// checkFunc 检查在新 goroutine 中运行的函数 fun 是否已 recover
+// node 是 go 语句或者 launcher 的参数，launcher 为空表示是 go 语句
+func (c *checker) checkFunc(node ast.Node, fun ast.Expr, launcher string) (ok bool) {
+	rc := &recovery{}
+	var reason string
+	defer func() {
+		g := rc.goroutine(c.pass, node, launcher, ok, reason)
+		g.Test = c.isTestFile(node.Pos())
+		c.result.add(g)
+	}()
+	ok, reason = c.checkFuncWith(rc, node, unwrapPassThrough(c.pass, fun), launcher)
+	if ok && rc.dynamic {
+		// 不影响退出码，只是让未检查的 goroutine 可见
+		reportf(c.pass, RuleDynamic, node, "goroutine not checked, %s can't be determined statically", rc.skip)
+	}
+	return ok
+}
+
+func (c *checker) checkFuncWith(rc *recovery, node ast.Node, fun ast.Expr, launcher string) (ok bool, reason string) {
+	pass := c.pass
+	kind := "GoStmt"
+	if launcher != "" {
+		kind = "Launcher " + launcher
+	}
+
+	// 报告中的代码片段，带有上一行代码以及对 go 语句的标记
+	code1 := asthelper.NodeSnippet(pass, node, asthelper.SnippetOptions{Context: 1, MaxLines: 10})
 	defer func() {
 		if re := recover(); re != nil {
 			bf := make([]byte, 4096)
 			n := runtime.Stack(bf, false)
-			pass.Reportf(gs.Pos(), "panic: %v, please report a bug,\nGoStmt Code:\n%s\nStack:\n%s", re, code1, bf[:n])
+			zpass.NewReporter(pass).Report(RuleInternal, analysis.Diagnostic{
+				Pos:     node.Pos(),
+				End:     node.End(),
+				Message: fmt.Sprintf("panic: %v, please report a bug", re),
+				Related: []analysis.RelatedInformation{
+					zpass.Related(node, "%s Code:\n%s", kind, code1),
+					zpass.Related(node, "Stack:\n%s", bf[:n]),
+				},
+			})
 		}
 	}()
 
 	defer func() {
-		if !ok {
+		if !ok || !zpass.IsDebugVerbose() {
 			return
 		}
-		successID++
-
-		var skipped string
-		if recoverAt == gs {
-			skipped = "(skipped or don't need recover)"
-		}
 
-		str1 := color.CyanString("[%d] GoStmt recovered >> %s\n", successID, asthelper.NodeLineNo(pass, gs))
-		str2 := color.GreenString("\nrecover() at %s %s\n", asthelper.NodeLineNo(pass, recoverAt), skipped)
-		code2 := asthelper.NodeCode(pass, recoverAt, 2)
-		if zpass.IsDebugVerbose() {
-			log.Println(str1 + code1 + str2 + code2)
+		str1 := color.CyanString("[%d] %s recovered >> %s\n", c.result.Recovered+c.result.Skipped+1, kind, asthelper.NodeLineNo(pass, node))
+		var str2, code2 string
+		switch {
+		case rc.skip != "":
+			str2 = color.GreenString("\nskipped: %s\n", rc.skip)
+		case len(rc.rf.Chain) > 0:
+			// 通过 RecoversFact 判断的，recover() 可能在其他 package 中
+			at := "returned func"
+			if rc.rf.At != "" {
+				at = asthelper.RelName(rc.rf.At)
+			}
+			str2 = color.GreenString("\nrecover() at %s via %s\n", at, chainString(rc.rf.Chain))
+		default:
+			str2 = color.GreenString("\nrecover() at %s\n", asthelper.NodeLineNo(pass, rc.at))
+			code2 = asthelper.NodeCode(pass, rc.at, 2)
 		}
+		log.Println(str1 + asthelper.NodeCode(pass, node, 10) + str2 + code2)
 	}()
 
-	checkIdent := func(id *ast.Ident) bool {
-		ok1, err1 := isIdentRecover(pass, id)
-		if err1 != nil {
-			pass.Reportf(gs.Pos(), err1.Error())
-		}
-		return ok1
-	}
-
-	switch vt0 := gs.Call.Fun.(type) {
+	switch vt0 := astutil.Unparen(fun).(type) {
 	case *ast.FuncLit:
 		// go func(){}
-		if isBlockStmtRecovered(vt0.Body) {
-			return true
+		rf, at, reason1 := bodyRecovered(pass, vt0.Body, factResolver(pass))
+		if rf != nil {
+			rc.set(rf, at)
+			return true, ""
 		}
-		if len(vt0.Body.List) == 1 {
-			if ex, ok1 := vt0.Body.List[0].(*ast.ExprStmt); ok1 && isExprStmtRecovered(pass, ex) {
-				return true
+		reason = reason1
+	case *ast.CallExpr:
+		// go factory()()
+		if ok1, reason1, found := c.ssaRecovered(fun, rc); found {
+			if ok1 {
+				return true, ""
 			}
+			reason = reason1
+			break
 		}
-
-	case *ast.Ident:
-		if vt0.Obj == nil {
-			// go panic("hello")
-			if vt0.Name == "panic" {
-				return true
-			}
-
-			// go noPanic(fn1)
-			if checkIdent(vt0) {
-				return true
+		if isFactoryRecovered(pass, vt0, rc) {
+			return true, ""
+		}
+	default:
+		if ok1, reason1, found := c.ssaRecovered(fun, rc); found {
+			if ok1 {
+				return true, ""
 			}
+			reason = reason1
 			break
 		}
-		// go goFuncWithoutRecover()
-		fd, ok := vt0.Obj.Decl.(*ast.FuncDecl) // fd 是 goFuncWithoutRecover 定义
-		if !ok {
-			return true
-		}
-		if isBlockStmtRecovered(fd.Body) {
-			return true
+		ok1, reason1, err1 := isFuncValueRecovered(pass, fun, rc)
+		if err1 != nil {
+			reportf(pass, RuleInternal, node, "%s", err1.Error())
 		}
-	case *ast.SelectorExpr:
-		// go abc.fn1(user.fn2)
-		if checkIdent(vt0.Sel) {
-			return true
+		if ok1 {
+			return true, ""
 		}
-	// case *ast.IndexExpr:
-	// go task.jobs[i]()
-	default:
-		pass.Reportf(gs.Pos(), "unsupported type: %T", gs.Call.Fun)
+		reason = reason1
 	}
-	failID++
-	pass.Reportf(gs.Pos(), "[%d] goroutine not recovered, func type is %T \n%s", failID, gs.Call.Fun, code1)
-	return false
-}
-
-func isIdentRecover(pass *analysis.Pass, id *ast.Ident) (bool, error) {
-	ov := pass.TypesInfo.ObjectOf(id)
-	if ov == nil {
-		return false, fmt.Errorf("object is nil for:%s", id.String())
+	if c.ig.ignored(node.Pos()) {
+		rc.skip = "ignored by " + ignoreDirective
+		return true, ""
 	}
-	ap, astFile := findAstFileByObject(pass, ov)
-	if astFile == nil {
-		return false, errors.New("cannot find *ast.File")
+	msg := reason
+	if msg != "" {
+		msg = ", " + msg
 	}
-
-	pass = ap
-
-	funcNode := findFuncDeclNode(astFile, id.Name)
-	if funcNode == nil {
-		return false, errors.New("cannot find *ast.FuncDecl")
-	}
-	if isBlockStmtRecovered(funcNode.Body) {
-		return true, nil
+	if launcher != "" {
+		msg += ", launched by " + launcher
 	}
+	zpass.NewReporter(pass).Report(RuleUnrecovered, analysis.Diagnostic{
+		Pos:            node.Pos(),
+		End:            node.End(),
+		Message:        fmt.Sprintf("[%d] goroutine not recovered, func type is %T%s", c.result.Unrecovered+1, fun, msg),
+		Related:        []analysis.RelatedInformation{zpass.Related(node, "code:\n%s", code1)},
+		SuggestedFixes: c.suggestFixes(node, fun),
+	})
+	return false, reason
+}
 
-	// 多个层级的调用
-	// func abc(fn func()){
-	// 	   xba.noPanic(fn)
-	// }
-	if funcNode.Body != nil && len(funcNode.Body.List) == 1 {
-		if ep, ok1 := funcNode.Body.List[0].(*ast.ExprStmt); ok1 && isExprStmtRecovered(pass, ep) {
-			return true, nil
-		}
+// ssaRecovered -mode=ssa 时，使用 SSA 判断 f := func(){}; go f() 这类变量中的函数是否已 recover，
+// 找不到可能的函数时返回 found=false，由 AST 的方式继续判断
+func (c *checker) ssaRecovered(fun ast.Expr, rc *recovery) (ok bool, reason string, found bool) {
+	sc := c.ssaChecker()
+	if sc == nil || !isDynamicFunc(c.pass, fun) {
+		return false, "", false
 	}
-	return false, nil
+	ok, reason, found = sc.recovered(fun, rc)
+	if ok {
+		rc.skip, rc.dynamic = "", false
+	}
+	return ok, reason, found
 }
 
-func isExprStmtRecovered(pass *analysis.Pass, nd *ast.ExprStmt) bool {
-	if ce, ok2 := nd.X.(*ast.CallExpr); ok2 {
-		if ceID, ok3 := ce.Fun.(*ast.Ident); ok3 && ceID.Obj != nil {
-			if o1, ok4 := ceID.Obj.Decl.(*ast.FuncDecl); ok4 {
-				if isBlockStmtRecovered(o1.Body) {
-					return true
-				}
-			}
+// isFuncValueRecovered 通过类型信息判断函数 fun 是否已 recover，
+// 已 recover 时，recover() 的位置记录到 rc
+// 支持：
+// go fn(), go pkg.Fn(), go obj.Method(), go run[T](x), go task.jobs[i](), go s.handlers[name](ctx)
+// 其中局部变量会检查赋值给它的函数，参数、struct 字段、map 中的函数等静态无法确定，跳过并以 info 级别报告
+func isFuncValueRecovered(pass *analysis.Pass, fun ast.Expr, rc *recovery) (ok bool, reason string, err error) {
+	// typeutil.Callee 只使用了 Fun 字段
+	switch vt := typeutil.Callee(pass.TypesInfo, &ast.CallExpr{Fun: fun}).(type) {
+	case *types.Builtin:
+		// go panic("hello")
+		if vt.Name() == "panic" {
+			rc.skip = "panic, don't need recover"
+			return true, "", nil
 		}
-
-		if se, ok3 := ce.Fun.(*ast.SelectorExpr); ok3 {
-			ok4, err := isIdentRecover(pass, se.Sel)
-			if ok4 {
-				return true
-			}
-			if err != nil {
-				pass.Reportf(se.Pos(), err.Error())
+		return false, "", nil
+	case *types.Func:
+		if isInterfaceMethod(vt) {
+			// go worker.Run()，运行时才能确定具体实现
+			rc.skipDynamic("interface method " + funcName(vt))
+			return true, "", nil
+		}
+		rf, reason := chainTo(pass, vt, importFact(pass, vt))
+		if rf == nil {
+			return false, reason, nil
+		}
+		rc.set(rf, nil)
+		return true, "", nil
+	case *types.Var:
+		// go f()，f 是局部变量时，检查赋值给它的所有函数，如 f := func(){}
+		if values, ok := localFuncValues(pass, vt); ok {
+			if ok1, reason1, known := funcValuesRecovered(pass, values, rc); known {
+				return ok1, reason1, nil
 			}
 		}
-	}
-	return false
-}
-
-func findFuncDeclNode(f *ast.File, name string) *ast.FuncDecl {
-	for _, d := range f.Decls {
-		if funcDecl, ok := d.(*ast.FuncDecl); ok && funcDecl.Name.Name == name {
-			return funcDecl
+		// 参数、struct 字段或者赋值为函数调用的结果等，静态无法确定
+		rc.skipDynamic("func value " + vt.Name())
+		return true, "", nil
+	case nil:
+		// go task.jobs[i]()，go s.handlers[name](ctx)
+		if tv, ok := pass.TypesInfo.Types[fun]; ok && isFuncType(tv.Type) {
+			rc.skipDynamic("dynamic func " + types.ExprString(fun))
+			return true, "", nil
 		}
+		return false, "", fmt.Errorf("cannot resolve callee: %s", types.ExprString(fun))
+	default:
+		return false, "", fmt.Errorf("unsupported callee: %T", vt)
 	}
-	return nil
 }
 
-func findAstFileByObject(pass *analysis.Pass, ov types.Object) (*analysis.Pass, *ast.File) {
-	ap, f, err := container.FindAstFileByObject(ov)
-	if err == nil {
-		return ap, f
+// isFactoryRecovered 判断 go factory()() 中 factory 返回的函数是否都已 recover
+func isFactoryRecovered(pass *analysis.Pass, call *ast.CallExpr, rc *recovery) bool {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil {
+		// go fns[i]()()，go fn()()
+		rc.skipDynamic("dynamic factory " + types.ExprString(call.Fun))
+		return true
 	}
-	pass.Reportf(ov.Pos(), err.Error())
-	return nil, nil
-}
-
-var recoverAt ast.Node
-
-func isBlockStmtRecovered(bs *ast.BlockStmt) bool {
-	// func body empty when with go:linkname
-	if bs == nil {
+	rf := importFact(pass, fn)
+	if rf == nil || !rf.Returns {
 		return false
 	}
-	for _, blockStmt := range bs.List {
-		deferStmt, ok := blockStmt.(*ast.DeferStmt) // 是否包含defer 语句
-		if !ok {
-			continue
-		}
-		switch vt0 := deferStmt.Call.Fun.(type) {
-		case *ast.FuncLit:
-			// 判断是否有 defer func(){ }()
-			for i := range vt0.Body.List {
-				stmt := vt0.Body.List[i]
-				if isStmtRecovered(stmt) {
-					recoverAt = stmt
-					return true
-				}
-			}
-		}
-	}
-	return false
+	rc.set(&RecoversFact{Chain: []string{funcName(fn)}}, nil)
+	return true
 }
 
-// 判断下列：
-// recover()
-// _=recover()
-// if re:=recover();re!=nil{
-func isStmtRecovered(stmt ast.Stmt) bool {
-	switch vt1 := stmt.(type) {
-	case *ast.ExprStmt:
-		// recover()
-		if isRecoverExpr(vt1.X) {
-			return true
-		}
-	case *ast.IfStmt:
-		// if r:=recover();r!=nil{}
-		as, ok := vt1.Init.(*ast.AssignStmt)
+// unwrapPassThrough 返回实际运行的函数，如 go sync.OnceFunc(fn)() 中的 fn
+func unwrapPassThrough(pass *analysis.Pass, fun ast.Expr) ast.Expr {
+	for {
+		call, ok := astutil.Unparen(fun).(*ast.CallExpr)
 		if !ok {
-			return false
-		}
-		if isRecoverExpr(as.Rhs[0]) {
-			return true
+			return fun
 		}
-	case *ast.AssignStmt:
-		// r=:recover
-		if isRecoverExpr(vt1.Rhs[0]) {
-			return true
+		arg := passThroughArg(pass, call)
+		if arg == nil {
+			return fun
 		}
+		fun = arg
 	}
-	return false
 }
 
-func isRecoverExpr(expr ast.Expr) bool {
-	ac, ok := expr.(*ast.CallExpr) // r:=recover()
-	if !ok {
-		return false
+// passThroughArg 若 call 是 sync.OnceFunc(fn) 这类会调用参数中函数的，返回该参数
+func passThroughArg(pass *analysis.Pass, call *ast.CallExpr) ast.Expr {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil || len(call.Args) != 1 || !matchFunc(passThroughFuncs, fn) {
+		return nil
 	}
-	id, ok := ac.Fun.(*ast.Ident)
-	if !ok {
-		return false
-	}
-	if id.Name == "recover" {
-		return true
-	}
-	return false
+	return call.Args[0]
+}
+
+func isInterfaceMethod(fn *types.Func) bool {
+	recv := fn.Type().(*types.Signature).Recv()
+	return recv != nil && types.IsInterface(recv.Type())
+}
+
+func isFuncType(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Signature)
+	return ok
 }
@@ -0,0 +1,154 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/fsgo/gocode/zpass"
+)
+
+// TestAnalyzer testdata/src 下为 GOPATH 结构的测试代码，
+// 使用 // want "..." 标注期望的诊断信息，// want fn:"..." 标注期望的 RecoversFact
+// wrapper 只作为依赖，用于测试跨 package 的情况
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "demo", "generic")
+}
+
+// TestSuggestedFixes 修复后的代码见 testdata/src/fix 下的 .golden 文件
+func TestSuggestedFixes(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "fix")
+}
+
+// setFlags 设置 Analyzer 的参数，如 setFlags(t, "mode", "ssa")，
+// 测试结束后恢复默认值，已读取的配置会被清除，以便使用新的参数
+func setFlags(t *testing.T, nameValues ...string) {
+	t.Helper()
+	reset := func() {
+		analysisMode = modeAST
+		container.Tests = ""
+		trustedModules = nil
+		configs = sync.Map{}
+	}
+	reset()
+	t.Cleanup(reset)
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		if err := Analyzer.Flags.Set(nameValues[i], nameValues[i+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestModeSSA(t *testing.T) {
+	setFlags(t, "mode", "ssa")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "ssamode")
+}
+
+// TestSSAPackages ssamode 只导入了 ssadep，间接依赖的 ssadep/inner 也需要创建 SSA package
+func TestSSAPackages(t *testing.T) {
+	a := &analysis.Analyzer{
+		Name:       "gorecover_ssa_packages",
+		Doc:        "created ssa packages",
+		ResultType: reflect.TypeOf(false),
+		Run: func(pass *analysis.Pass) (any, error) {
+			sc := newSSAChecker(pass)
+			return sc.pkg.Prog.ImportedPackage("ssadep/inner") != nil, nil
+		},
+	}
+	// 忽略 ssamode 中的 // want
+	rs := analysistest.Run(discard{}, analysistest.TestData(), a, "ssamode")
+	if len(rs) != 1 || rs[0].Err != nil || rs[0].Result != true {
+		t.Fatalf("ssadep/inner not created: %+v", rs)
+	}
+}
+
+func TestTrustedModules(t *testing.T) {
+	setFlags(t, "trusted-modules", "std")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "trusted")
+}
+
+// TestTests testdata/src/tests 下的 package 名为 -tests 的值
+func TestTests(t *testing.T) {
+	for _, mode := range []zpass.TestMode{zpass.TestSkip, zpass.TestWarn, zpass.TestReport} {
+		t.Run(string(mode), func(t *testing.T) {
+			setFlags(t, "tests", string(mode))
+			analysistest.Run(t, analysistest.TestData(), Analyzer, "tests/"+string(mode))
+		})
+	}
+}
+
+// discard 忽略 analysistest 检查 // want 的结果，用于 -baseline-update 时
+type discard struct{}
+
+func (discard) Errorf(string, ...any) {}
+
+func TestBaseline(t *testing.T) {
+	setFlags(t)
+	name := filepath.Join(t.TempDir(), "baseline.json")
+	resetBaseline := func() {
+		baseline = &baselines{}
+	}
+	resetBaseline()
+	t.Cleanup(resetBaseline)
+
+	// 复制到临时目录，之后在 fn1 前插入空行，改变问题的位置；
+	// 基线只处理当前目录下的文件，临时目录也在 testdata 下
+	dir, err := os.MkdirTemp(analysistest.TestData(), "shifted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+	src, err := os.ReadFile(filepath.Join(analysistest.TestData(), "src", "baseline", "baseline.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "src", "baseline", "baseline.go")
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(file, src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 先记录所有的问题，再去掉 fn2 的，fn2 中的问题是新的
+	setBaselineFlags(t, name, true)
+	analysistest.Run(discard{}, dir, Analyzer, "baseline")
+	bl, err := LoadBaseline(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []Finding
+	for _, f := range bl.Findings {
+		if f.Func != "fn2" {
+			kept = append(kept, f)
+		}
+	}
+	if len(kept) != 3 || len(bl.Findings) != 4 {
+		t.Fatalf("got findings %v, want 3 in fn1 and 1 in fn2", bl.Findings)
+	}
+	bl.Findings = kept
+	if err = bl.WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+
+	// 所有代码下移 3 行，fn1 中的问题依然在基线中
+	shifted := strings.Replace(string(src), "\nfunc fn1()", "\n\n\n\nfunc fn1()", 1)
+	if err = os.WriteFile(file, []byte(shifted), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resetBaseline()
+	setBaselineFlags(t, name, false)
+	analysistest.Run(t, dir, Analyzer, "baseline")
+}
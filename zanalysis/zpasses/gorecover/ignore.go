@@ -0,0 +1,123 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	// ignoreDirective 在 go 语句、函数的上一行，或者 go 语句的行尾添加，忽略其中的问题，需要写明原因：
+	//
+	//	//gorecover:ignore 不会 panic
+	//	go fn()
+	//	go fn() //gorecover:ignore 不会 panic
+	ignoreDirective = "//gorecover:ignore"
+
+	// fileIgnoreDirective 忽略整个文件
+	fileIgnoreDirective = "//gorecover:file-ignore"
+)
+
+// hasFileIgnore 文件中是否有 //gorecover:file-ignore
+func hasFileIgnore(f *ast.File) bool {
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if isDirective(c.Text, fileIgnoreDirective) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isDirective(text string, directive string) bool {
+	after, ok := strings.CutPrefix(text, directive)
+	return ok && (after == "" || after[0] == ' ' || after[0] == '\t')
+}
+
+type posRange struct {
+	start token.Pos
+	end   token.Pos
+}
+
+// ignorer 记录 //gorecover:ignore 忽略的代码范围
+type ignorer struct {
+	ranges []posRange
+}
+
+// addFile 读取文件中的 //gorecover:ignore：
+//   - 单独一行的，忽略下一行开始的语句
+//   - 在行尾的，只忽略同一行开始的语句，如 go fn() //gorecover:ignore 原因
+func (ig *ignorer) addFile(pass *analysis.Pass, f *ast.File) {
+	directives := make(map[int]token.Pos) // 行号 -> 注释的位置
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if !isDirective(c.Text, ignoreDirective) {
+				continue
+			}
+			if strings.TrimSpace(strings.TrimPrefix(c.Text, ignoreDirective)) == "" {
+				reportf(pass, RuleIgnore, c, "%s needs a reason", ignoreDirective)
+			}
+			directives[pass.Fset.Position(c.Pos()).Line] = c.Pos()
+		}
+	}
+	if len(directives) == 0 {
+		return
+	}
+
+	// 同一行中，注释之前有代码的是行尾的注释
+	trailing := make(map[int]bool)
+	ast.Inspect(f, func(node ast.Node) bool {
+		switch node.(type) {
+		case nil, *ast.File, *ast.Comment, *ast.CommentGroup:
+			return true
+		}
+		line := pass.Fset.Position(node.End()).Line
+		if pos, ok := directives[line]; ok && node.End() <= pos {
+			trailing[line] = true
+		}
+		return true
+	})
+	ignoredAt := func(line int) bool {
+		if _, ok := directives[line-1]; ok && !trailing[line-1] {
+			return true
+		}
+		_, ok := directives[line]
+		return ok && trailing[line]
+	}
+
+	ast.Inspect(f, func(node ast.Node) bool {
+		switch vt := node.(type) {
+		case *ast.FuncDecl:
+			if vt.Doc != nil {
+				for _, c := range vt.Doc.List {
+					if isDirective(c.Text, ignoreDirective) {
+						ig.ranges = append(ig.ranges, posRange{start: vt.Pos(), end: vt.End()})
+						return false
+					}
+				}
+			}
+		case ast.Stmt:
+			if ignoredAt(pass.Fset.Position(vt.Pos()).Line) {
+				ig.ranges = append(ig.ranges, posRange{start: vt.Pos(), end: vt.End()})
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (ig *ignorer) ignored(pos token.Pos) bool {
+	for _, r := range ig.ranges {
+		if pos >= r.start && pos < r.end {
+			return true
+		}
+	}
+	return false
+}
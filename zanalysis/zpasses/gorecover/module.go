@@ -0,0 +1,74 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"go/build"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// moduleStd 标准库的 module 名称，用于 trusted_modules 配置
+const moduleStd = "std"
+
+// ModuleFact 导出在 package 上的 fact，记录 package 所在的 module，
+// 用于判断依赖的 package 中的函数是否在可信的 module 中
+type ModuleFact struct {
+	Path    string
+	Version string
+}
+
+func (*ModuleFact) AFact() {}
+
+func (f *ModuleFact) String() string {
+	if f.Version == "" {
+		return f.Path
+	}
+	return f.Path + "@" + f.Version
+}
+
+// exportModuleFact 导出当前 package 的 ModuleFact，标准库的 module 为 std，
+// 没有 module 信息(如 GOPATH 模式)的 package 不导出
+func exportModuleFact(pass *analysis.Pass) {
+	switch {
+	case pass.Module != nil && pass.Module.Path != "":
+		pass.ExportPackageFact(&ModuleFact{Path: pass.Module.Path, Version: pass.Module.Version})
+	case isStdPkg(pass):
+		pass.ExportPackageFact(&ModuleFact{Path: moduleStd})
+	}
+}
+
+// isStdPkg 是否是标准库，标准库的文件都在 GOROOT/src 下
+func isStdPkg(pass *analysis.Pass) bool {
+	if len(pass.Files) == 0 || build.Default.GOROOT == "" {
+		return false
+	}
+	name := pass.Fset.File(pass.Files[0].Pos()).Name()
+	return strings.HasPrefix(name, filepath.Join(build.Default.GOROOT, "src")+string(filepath.Separator))
+}
+
+// moduleOf 返回 package 所在的 module，无法确定时返回 nil
+func moduleOf(pass *analysis.Pass, pkg *types.Package) *ModuleFact {
+	mf := &ModuleFact{}
+	if pkg == nil || !pass.ImportPackageFact(pkg, mf) {
+		return nil
+	}
+	return mf
+}
+
+// externalModule 若函数 fn 在其他 module 中，返回其 module
+func externalModule(pass *analysis.Pass, fn *types.Func) *ModuleFact {
+	if fn.Pkg() == nil || fn.Pkg() == pass.Pkg {
+		return nil
+	}
+	mf := moduleOf(pass, fn.Pkg())
+	if mf == nil || (pass.Module != nil && mf.Path == pass.Module.Path) {
+		return nil
+	}
+	return mf
+}
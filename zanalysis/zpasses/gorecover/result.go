@@ -0,0 +1,150 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/fsgo/gocode/zpass"
+)
+
+// Status goroutine 的检查结果
+type Status string
+
+const (
+	StatusRecovered   Status = "recovered"   // 已 recover
+	StatusUnrecovered Status = "unrecovered" // 未 recover
+	StatusSkipped     Status = "skipped"     // 不需要 recover、静态无法确定或者已忽略
+)
+
+// Goroutine 一个 go 语句或者 launcher 参数的检查结果
+type Goroutine struct {
+	Pos      token.Position // go 语句或者 launcher 参数的位置
+	Launcher string         `json:",omitempty"` // 启动 goroutine 的函数，为空表示 go 语句
+	Status   Status
+
+	// RecoverAt 保护该 goroutine 的 recover() 的位置
+	RecoverAt string `json:",omitempty"`
+
+	// Chain 通过调用链 recover 时，从 goroutine 运行的函数到 recover() 所在函数的调用链
+	Chain []string `json:",omitempty"`
+
+	// Reason 未 recover 或者跳过的原因
+	Reason string `json:",omitempty"`
+
+	// Test 是否在测试代码中
+	Test bool `json:",omitempty"`
+}
+
+// Result Analyzer 的结果，一个 package 中所有 goroutine 的检查结果
+type Result struct {
+	Package     string
+	Recovered   int
+	Unrecovered int
+	Skipped     int
+	Goroutines  []*Goroutine
+}
+
+func (r *Result) add(g *Goroutine) {
+	switch g.Status {
+	case StatusRecovered:
+		r.Recovered++
+	case StatusUnrecovered:
+		r.Unrecovered++
+	case StatusSkipped:
+		r.Skipped++
+	}
+	r.Goroutines = append(r.Goroutines, g)
+}
+
+// Total goroutine 总数
+func (r *Result) Total() int {
+	return r.Recovered + r.Unrecovered + r.Skipped
+}
+
+// Summary 多个 package 的汇总结果
+type Summary struct {
+	Recovered   int
+	Unrecovered int
+	Skipped     int
+
+	// Packages 每个 package 的结果，按照 package 排序
+	Packages []*Result
+}
+
+// NewSummary 汇总多个 package 的结果
+// 同一个 package 可能会被分析多次(如包含测试文件时)，同一位置的 goroutine 只计算一次
+func NewSummary(results []*Result) *Summary {
+	pkgs := make(map[string]*Result)
+	seen := make(map[token.Position]bool)
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		pr, ok := pkgs[r.Package]
+		if !ok {
+			pr = &Result{Package: r.Package}
+			pkgs[r.Package] = pr
+		}
+		for _, g := range r.Goroutines {
+			if seen[g.Pos] {
+				continue
+			}
+			seen[g.Pos] = true
+			pr.add(g)
+		}
+	}
+	s := &Summary{}
+	for _, pr := range pkgs {
+		s.Recovered += pr.Recovered
+		s.Unrecovered += pr.Unrecovered
+		s.Skipped += pr.Skipped
+		s.Packages = append(s.Packages, pr)
+	}
+	sort.Slice(s.Packages, func(i, j int) bool {
+		return s.Packages[i].Package < s.Packages[j].Package
+	})
+	return s
+}
+
+// WriteTable 以表格的形式输出，不包含没有 goroutine 的 package
+func (s *Summary) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Package\tRecovered\tUnrecovered\tSkipped\tTotal")
+	for _, pr := range s.Packages {
+		if pr.Total() == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", pr.Package, pr.Recovered, pr.Unrecovered, pr.Skipped, pr.Total())
+	}
+	total := s.Recovered + s.Unrecovered + s.Skipped
+	fmt.Fprintf(tw, "Total\t%d\t%d\t%d\t%d\n", s.Recovered, s.Unrecovered, s.Skipped, total)
+	return tw.Flush()
+}
+
+// WriteJSON 以 json 格式输出，Pos、RecoverAt 中的文件路径按照 -path-style 格式输出，和诊断信息一致
+func (s *Summary) WriteJSON(w io.Writer) error {
+	out := *s
+	out.Packages = make([]*Result, 0, len(s.Packages))
+	for _, pr := range s.Packages {
+		r := *pr
+		r.Goroutines = make([]*Goroutine, 0, len(pr.Goroutines))
+		for _, g := range pr.Goroutines {
+			gc := *g
+			gc.Pos.Filename = zpass.RelPath(g.Pos.Filename)
+			gc.RecoverAt = zpass.RelPath(g.RecoverAt)
+			r.Goroutines = append(r.Goroutines, &gc)
+		}
+		out.Packages = append(out.Packages, &r)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&out)
+}
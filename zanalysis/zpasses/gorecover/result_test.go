@@ -0,0 +1,69 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"encoding/json"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fsgo/gocode/zpass"
+)
+
+func TestSummaryWriteJSON(t *testing.T) {
+	abs, err := filepath.Abs("result.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Goroutine{
+		Pos:       token.Position{Filename: abs, Line: 3, Column: 2},
+		Status:    StatusRecovered,
+		RecoverAt: abs + ":5:6",
+	}
+	safe := &Goroutine{
+		Pos:       token.Position{Filename: abs, Line: 7, Column: 2},
+		Status:    StatusRecovered,
+		RecoverAt: "safe func example.com/safe.Go",
+	}
+	s := NewSummary([]*Result{{Package: "demo", Goroutines: []*Goroutine{g, safe}}})
+
+	t.Cleanup(func() {
+		_ = zpass.SetPathStyle(zpass.PathModule)
+	})
+	tests := []struct {
+		style zpass.PathStyle
+		want  string
+	}{
+		{style: zpass.PathModule, want: "zanalysis/zpasses/gorecover/result.go"},
+		{style: zpass.PathRelative, want: "result.go"},
+		{style: zpass.PathAbsolute, want: abs},
+	}
+	for _, tt := range tests {
+		if err = zpass.SetPathStyle(tt.style); err != nil {
+			t.Fatal(err)
+		}
+		bf := &strings.Builder{}
+		if err = s.WriteJSON(bf); err != nil {
+			t.Fatal(err)
+		}
+		var got Summary
+		if err = json.Unmarshal([]byte(bf.String()), &got); err != nil {
+			t.Fatal(err)
+		}
+		gs := got.Packages[0].Goroutines
+		if gs[0].Pos.Filename != tt.want || gs[0].RecoverAt != tt.want+":5:6" {
+			t.Errorf("%s: got pos %q, recover at %q, want %q", tt.style, gs[0].Pos.Filename, gs[0].RecoverAt, tt.want)
+		}
+		if gs[1].RecoverAt != safe.RecoverAt {
+			t.Errorf("%s: got recover at %q, want %q", tt.style, gs[1].RecoverAt, safe.RecoverAt)
+		}
+	}
+	// 不修改原来的结果
+	if g.Pos.Filename != abs || s.Packages[0].Goroutines[0] != g {
+		t.Fatalf("summary changed: %+v", s.Packages[0].Goroutines[0])
+	}
+}
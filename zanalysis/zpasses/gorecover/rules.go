@@ -0,0 +1,70 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/fsgo/gocode/zpass"
+)
+
+const docURL = "https://github.com/fsgo/gocode/blob/master/cmd/go-recover/README.md"
+
+// 报告的诊断信息的规则，规则的 ID 是诊断信息的 Category
+var (
+	RuleUnrecovered = &zpass.Rule{
+		ID:       "go_recover/unrecovered",
+		Category: "reliability",
+		URL:      docURL + "#rules",
+		Doc:      "goroutine not recovered, a panic in it crashes the whole process",
+	}
+
+	RuleIneffective = &zpass.Rule{
+		ID:       "go_recover/ineffective",
+		Category: "reliability",
+		URL:      docURL + "#rules",
+		Doc:      "recover() is not called directly by a deferred func, it has no effect",
+	}
+
+	// RuleSwallow 吞掉 panic 不一定是错误(如有意忽略的)，默认为警告，不影响退出码
+	RuleSwallow = &zpass.Rule{
+		ID:       "go_recover/swallow",
+		Severity: zpass.SeverityWarning,
+		Category: "reliability",
+		URL:      docURL + "#swallowed-panics",
+		Doc:      "recover() discards the recovered value without reporting or re-panic",
+	}
+
+	RuleIgnore = &zpass.Rule{
+		ID:       "go_recover/ignore",
+		Category: "style",
+		URL:      docURL + "#ignore",
+		Doc:      "//gorecover:ignore needs a reason",
+	}
+
+	RuleDynamic = &zpass.Rule{
+		ID:       "go_recover/dynamic",
+		Severity: zpass.SeverityInfo,
+		Category: "reliability",
+		URL:      docURL + "#rules",
+		Doc:      "the func run by the goroutine can't be determined statically, it's not checked, try -mode=ssa",
+	}
+
+	RuleInternal = &zpass.Rule{
+		ID:       "go_recover/internal",
+		Category: "internal",
+		URL:      docURL + "#rules",
+		Doc:      "the goroutine can't be checked, please report a bug",
+	}
+)
+
+func init() {
+	zpass.RegisterRules(RuleUnrecovered, RuleIneffective, RuleSwallow, RuleIgnore, RuleDynamic, RuleInternal)
+}
+
+// reportf 使用 zpass.Reporter 报告规则 rule 在 rng 处的诊断信息
+func reportf(pass *analysis.Pass, rule *zpass.Rule, rng analysis.Range, format string, args ...any) {
+	zpass.NewReporter(pass).Reportf(rule, rng, format, args...)
+}
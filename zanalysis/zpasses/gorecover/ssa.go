@@ -0,0 +1,341 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/fsgo/gocode/internal/asthelper"
+)
+
+// 分析模式，由 -mode 参数指定
+const (
+	modeAST = "ast"
+	modeSSA = "ssa"
+)
+
+var analysisMode = modeAST
+
+func init() {
+	Analyzer.Flags.Func("mode", "analysis mode: ast|ssa (default ast)\nssa also checks funcs stored in variables, e.g. f := func(){}; go f()", func(s string) error {
+		if s != modeAST && s != modeSSA {
+			return fmt.Errorf("unsupported mode %q", s)
+		}
+		analysisMode = s
+		return nil
+	})
+}
+
+// ssaChecker 使用 SSA 查找 go 语句可能运行的函数，
+// 用于 f := func(){}; go f() 以及 handler := pick(); go handler() 这类 AST 无法确定的场景
+type ssaChecker struct {
+	pass *analysis.Pass
+	pkg  *ssa.Package
+
+	// stores 全局变量 -> 赋值给它的值
+	stores map[*ssa.Global][]ssa.Value
+}
+
+// newSSAChecker 构建当前 package 的 SSA，和 buildssa 一样，但是包含调试信息，以便从 AST 找到对应的值
+//
+// 和 buildssa 一样为所有直接和间接依赖的 package 创建 SSA package(不包含函数体)，
+// 这样间接依赖的 package 中的函数、方法也是所属 package 的成员
+func newSSAChecker(pass *analysis.Pass) *ssaChecker {
+	prog := ssa.NewProgram(pass.Fset, ssa.GlobalDebug)
+	created := make(map[*types.Package]bool)
+	var createAll func(pkgs []*types.Package)
+	createAll = func(pkgs []*types.Package) {
+		for _, p := range pkgs {
+			if !created[p] {
+				created[p] = true
+				prog.CreatePackage(p, nil, nil, true)
+				createAll(p.Imports())
+			}
+		}
+	}
+	createAll(pass.Pkg.Imports())
+	pkg := prog.CreatePackage(pass.Pkg, pass.Files, pass.TypesInfo, false)
+	pkg.Build()
+	return &ssaChecker{
+		pass: pass,
+		pkg:  pkg,
+	}
+}
+
+// ssaChecker 返回 SSA 检查器，只在 -mode=ssa 时，第一次使用时构建
+func (c *checker) ssaChecker() *ssaChecker {
+	if analysisMode != modeSSA {
+		return nil
+	}
+	if c.ssa == nil {
+		c.ssa = newSSAChecker(c.pass)
+	}
+	return c.ssa
+}
+
+// isDynamicFunc fun 是否是变量、struct 字段等，静态无法确定运行的函数
+func isDynamicFunc(pass *analysis.Pass, fun ast.Expr) bool {
+	switch typeutil.Callee(pass.TypesInfo, &ast.CallExpr{Fun: fun}).(type) {
+	case *types.Var:
+		return true
+	case nil:
+		_, ok := astutil.Unparen(fun).(*ast.CallExpr)
+		return ok || isFuncType(pass.TypesInfo.TypeOf(fun))
+	}
+	return false
+}
+
+// recovered 判断 fun 所有可能的函数是否都已 recover
+// 找不到可能的函数时，返回 found=false；
+// 有无法确定的来源(如参数、struct 字段)时，不认为已 recover，也返回 found=false，由 AST 的方式继续判断
+func (sc *ssaChecker) recovered(fun ast.Expr, rc *recovery) (ok bool, reason string, found bool) {
+	fs := sc.funcsOf(fun)
+	var first *RecoversFact
+	var firstAt ast.Node
+	for _, fn := range fs.funcs {
+		rf, at, reason, known := sc.funcRecovered(fn)
+		if !known {
+			fs.unknown = true
+			continue
+		}
+		found = true
+		if rf == nil {
+			if reason == "" {
+				reason = "not recovered"
+			}
+			return false, fmt.Sprintf("possible func %s: %s", sc.funcName(fn), reason), true
+		}
+		if first == nil {
+			first, firstAt = rf, at
+		}
+	}
+	if !found || fs.unknown {
+		return false, "", false
+	}
+	if rc.rf == nil {
+		rc.set(first, firstAt)
+	}
+	return true, "", true
+}
+
+// funcSet trace 找到的可能的函数
+type funcSet struct {
+	seen  map[ssa.Value]bool
+	funcs []*ssa.Function
+	has   map[*ssa.Function]bool
+
+	// unknown 是否有无法确定的来源，如参数、struct 字段、map 中的值、接口方法的返回值
+	unknown bool
+}
+
+func (fs *funcSet) add(f *ssa.Function) {
+	if !fs.has[f] {
+		fs.has[f] = true
+		fs.funcs = append(fs.funcs, f)
+	}
+}
+
+// funcsOf 返回表达式 fun 可能的值对应的函数
+func (sc *ssaChecker) funcsOf(fun ast.Expr) *funcSet {
+	fs := &funcSet{
+		seen: make(map[ssa.Value]bool),
+		has:  make(map[*ssa.Function]bool),
+	}
+	fun = astutil.Unparen(fun)
+	file := fileOf(sc.pass, fun.Pos())
+	if file == nil {
+		return fs
+	}
+	path, _ := astutil.PathEnclosingInterval(file, fun.Pos(), fun.End())
+	fn := ssa.EnclosingFunction(sc.pkg, path)
+	if fn == nil {
+		return fs
+	}
+	v, _ := fn.ValueForExpr(fun)
+	if v == nil {
+		return fs
+	}
+	sc.trace(v, fs)
+	return fs
+}
+
+// trace 跟踪值的来源，找到可能的函数
+// 参数、struct 字段等无法确定的值，设置 fs.unknown
+func (sc *ssaChecker) trace(v ssa.Value, fs *funcSet) {
+	if fs.seen[v] {
+		return
+	}
+	fs.seen[v] = true
+	switch vt := v.(type) {
+	case *ssa.Function:
+		fs.add(vt)
+	case *ssa.MakeClosure:
+		if f, ok := vt.Fn.(*ssa.Function); ok {
+			fs.add(f)
+		} else {
+			fs.unknown = true
+		}
+	case *ssa.Const:
+		// var f func() 的零值 nil，运行时 panic，不是可能的函数
+	case *ssa.Phi:
+		// if cond { f = a } else { f = b }
+		for _, e := range vt.Edges {
+			sc.trace(e, fs)
+		}
+	case *ssa.ChangeType:
+		sc.trace(vt.X, fs)
+	case *ssa.UnOp:
+		// 被闭包引用的变量、全局变量，需要找到对其的赋值
+		if vt.Op != token.MUL {
+			fs.unknown = true
+			return
+		}
+		vs, ok := sc.storedValues(vt.X)
+		if !ok {
+			fs.unknown = true
+		}
+		for _, sv := range vs {
+			sc.trace(sv, fs)
+		}
+	case *ssa.Call:
+		// handler := pick()
+		sc.traceReturns(vt.Common(), 0, fs)
+	case *ssa.Extract:
+		// handler, err := pick()
+		if call, ok := vt.Tuple.(*ssa.Call); ok {
+			sc.traceReturns(call.Common(), vt.Index, fs)
+		} else {
+			fs.unknown = true
+		}
+	default:
+		fs.unknown = true
+	}
+}
+
+// traceReturns 跟踪当前 package 中函数的返回值，其他 package 的函数、接口方法等无法确定
+func (sc *ssaChecker) traceReturns(cc *ssa.CallCommon, index int, fs *funcSet) {
+	callee := cc.StaticCallee()
+	if callee == nil || callee.Pkg != sc.pkg || len(callee.Blocks) == 0 {
+		fs.unknown = true
+		return
+	}
+	for _, b := range callee.Blocks {
+		for _, instr := range b.Instrs {
+			if ret, ok := instr.(*ssa.Return); ok && index < len(ret.Results) {
+				sc.trace(ret.Results[index], fs)
+			}
+		}
+	}
+}
+
+// storedValues 返回赋值给地址 addr 的值，支持局部变量和当前 package 的全局变量，
+// 其他地址(如 struct 字段、slice 元素)或者地址被传递出去时，返回 ok=false
+func (sc *ssaChecker) storedValues(addr ssa.Value) (vs []ssa.Value, ok bool) {
+	switch vt := addr.(type) {
+	case *ssa.Alloc:
+		ok = true
+		for _, ref := range *vt.Referrers() {
+			switch rt := ref.(type) {
+			case *ssa.Store:
+				if rt.Addr == vt {
+					vs = append(vs, rt.Val)
+				} else {
+					ok = false
+				}
+			case *ssa.UnOp, *ssa.DebugRef:
+			case *ssa.MakeClosure:
+				// 被闭包引用，闭包中的赋值是对 FreeVar 的 Store，无法找到
+				ok = ok && !closureStores(rt, vt)
+			default:
+				ok = false
+			}
+		}
+		return vs, ok
+	case *ssa.Global:
+		if vt.Pkg != sc.pkg {
+			return nil, false
+		}
+		if sc.stores == nil {
+			sc.stores = sc.globalStores()
+		}
+		return sc.stores[vt], true
+	}
+	return nil, false
+}
+
+// closureStores 闭包中是否可能对其引用的变量 addr 赋值
+func closureStores(mc *ssa.MakeClosure, addr ssa.Value) bool {
+	fn, ok := mc.Fn.(*ssa.Function)
+	if !ok {
+		return true
+	}
+	for i, bv := range mc.Bindings {
+		if bv != addr || i >= len(fn.FreeVars) {
+			continue
+		}
+		for _, ref := range *fn.FreeVars[i].Referrers() {
+			switch ref.(type) {
+			case *ssa.UnOp, *ssa.DebugRef:
+			default:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// globalStores 返回当前 package 中所有对全局变量的赋值
+func (sc *ssaChecker) globalStores() map[*ssa.Global][]ssa.Value {
+	stores := make(map[*ssa.Global][]ssa.Value)
+	for fn := range ssautil.AllFunctions(sc.pkg.Prog) {
+		if fn.Pkg != sc.pkg {
+			continue
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				st, ok := instr.(*ssa.Store)
+				if !ok {
+					continue
+				}
+				if g, ok := st.Addr.(*ssa.Global); ok {
+					stores[g] = append(stores[g], st.Val)
+				}
+			}
+		}
+	}
+	return stores
+}
+
+// funcRecovered 判断函数是否已 recover，func 字面量检查其函数体，其他的使用 RecoversFact
+// 无法确定时返回 known=false
+func (sc *ssaChecker) funcRecovered(fn *ssa.Function) (rf *RecoversFact, at ast.Node, reason string, known bool) {
+	if fl, ok := fn.Syntax().(*ast.FuncLit); ok {
+		rf, at, reason = bodyRecovered(sc.pass, fl.Body, factResolver(sc.pass))
+		return rf, at, reason, true
+	}
+	obj, ok := fn.Object().(*types.Func)
+	if !ok {
+		// 编译器生成的函数，如方法值的包装函数
+		return nil, nil, "", false
+	}
+	rf, reason = chainTo(sc.pass, obj, importFact(sc.pass, obj))
+	return rf, nil, reason, true
+}
+
+func (sc *ssaChecker) funcName(fn *ssa.Function) string {
+	if _, ok := fn.Syntax().(*ast.FuncLit); ok {
+		return "func literal at " + asthelper.RelName(sc.pass.Fset.Position(fn.Pos()).String())
+	}
+	return fn.String()
+}
@@ -0,0 +1,178 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// checkSwallow 是否检查吞掉 panic 的 recover()，由 -swallow 参数指定，可以被配置文件覆盖
+var checkSwallow = true
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkSwallow, "swallow", checkSwallow, "report recover() which discards the recovered value without reporting or re-panic")
+}
+
+// 吞掉 panic 的 recover()，recover 到的值被丢弃，也没有记录日志、重新 panic：
+//
+//	defer func() { recover() }()
+//	defer func() { _ = recover() }()
+//	defer func() {
+//	    if r := recover(); r != nil {
+//	    }
+//	}()
+//
+// recover 到的值被使用(如传给其他函数、赋值给 err)，
+// 或者调用了 Config.ReportFuncs 中的函数、panic 时，不认为是吞掉了 panic
+
+// checkSwallowed 检查有效的 recover() 是否吞掉了 panic，
+// call 是 stack 的最后一个元素，body 是直接调用 recover() 的函数的函数体
+func checkSwallowed(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node, body *ast.BlockStmt) {
+	cfg, _ := getConfig(pass)
+	if cfg == nil || !cfg.Swallow {
+		return
+	}
+	vars, ok := recoveredVars(pass, call, stack[len(stack)-2])
+	if !ok {
+		// recover 到的值已被使用，如 log.Println(recover())
+		return
+	}
+	if usesVars(pass, body, vars) {
+		return
+	}
+	if hasReportCall(pass, cfg, handlerScope(call, stack, body)) {
+		return
+	}
+	reportf(pass, RuleSwallow, call, "recover() swallows the panic, the recovered value is discarded without reporting or re-panic")
+}
+
+// recoveredVars 返回保存 recover() 返回值的变量
+// recover 到的值直接被使用时(如作为函数参数、赋值给 struct 字段)，返回 ok=false
+func recoveredVars(pass *analysis.Pass, call *ast.CallExpr, parent ast.Node) (vars map[types.Object]bool, ok bool) {
+	vars = make(map[types.Object]bool)
+	switch vt := parent.(type) {
+	case *ast.ExprStmt:
+		// recover()
+		return vars, true
+	case *ast.BinaryExpr:
+		// if recover() != nil {}
+		return vars, isNilCompare(pass, vt)
+	case *ast.AssignStmt:
+		// _ = recover()，r := recover()，err = recover().(error)
+		for i, rhs := range vt.Rhs {
+			if rhs != call || i >= len(vt.Lhs) {
+				continue
+			}
+			id, ok := vt.Lhs[i].(*ast.Ident)
+			if !ok {
+				return nil, false
+			}
+			if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+				vars[obj] = true
+			}
+		}
+		return vars, true
+	case *ast.ValueSpec:
+		// var r = recover()
+		for i, v := range vt.Values {
+			if v != call || i >= len(vt.Names) {
+				continue
+			}
+			if obj := pass.TypesInfo.ObjectOf(vt.Names[i]); obj != nil {
+				vars[obj] = true
+			}
+		}
+		return vars, true
+	default:
+		return nil, false
+	}
+}
+
+// usesVars 函数体内是否使用了变量，和 nil 比较除外
+func usesVars(pass *analysis.Pass, body *ast.BlockStmt, vars map[types.Object]bool) bool {
+	if len(vars) == 0 {
+		return false
+	}
+	nilCompared := make(map[*ast.Ident]bool)
+	ast.Inspect(body, func(node ast.Node) bool {
+		if be, ok := node.(*ast.BinaryExpr); ok && isNilCompare(pass, be) {
+			for _, x := range []ast.Expr{be.X, be.Y} {
+				if id, ok := x.(*ast.Ident); ok {
+					nilCompared[id] = true
+				}
+			}
+		}
+		return true
+	})
+	var used bool
+	ast.Inspect(body, func(node ast.Node) bool {
+		id, ok := node.(*ast.Ident)
+		if !ok || used {
+			return !used
+		}
+		if vars[pass.TypesInfo.Uses[id]] && !nilCompared[id] {
+			used = true
+		}
+		return true
+	})
+	return used
+}
+
+// isNilCompare 是否是 x == nil 或者 x != nil
+func isNilCompare(pass *analysis.Pass, be *ast.BinaryExpr) bool {
+	if be.Op != token.EQL && be.Op != token.NEQ {
+		return false
+	}
+	return pass.TypesInfo.Types[be.X].IsNil() || pass.TypesInfo.Types[be.Y].IsNil()
+}
+
+// handlerScope 返回处理 panic 的代码：
+// recover() 在 if 语句的条件中时为 if 语句的 body 和 else，否则为整个函数体
+func handlerScope(call *ast.CallExpr, stack []ast.Node, body *ast.BlockStmt) []ast.Node {
+	for i := len(stack) - 2; i >= 0 && stack[i] != body; i-- {
+		is, ok := stack[i].(*ast.IfStmt)
+		if !ok {
+			continue
+		}
+		if contains(is.Init, call) || contains(is.Cond, call) {
+			scope := []ast.Node{is.Body}
+			if is.Else != nil {
+				scope = append(scope, is.Else)
+			}
+			return scope
+		}
+	}
+	return []ast.Node{body}
+}
+
+func contains(node ast.Node, call *ast.CallExpr) bool {
+	return node != nil && node.Pos() <= call.Pos() && call.End() <= node.End()
+}
+
+// hasReportCall 代码中是否调用了 panic，或者 Config.ReportFuncs 中的函数
+func hasReportCall(pass *analysis.Pass, cfg *Config, scope []ast.Node) bool {
+	var found bool
+	for _, node := range scope {
+		ast.Inspect(node, func(n ast.Node) bool {
+			ce, ok := n.(*ast.CallExpr)
+			if !ok || found {
+				return !found
+			}
+			switch fn := typeutil.Callee(pass.TypesInfo, ce).(type) {
+			case *types.Builtin:
+				found = fn.Name() == "panic"
+			case *types.Func:
+				found = cfg.IsReportFunc(fn)
+			}
+			return !found
+		})
+	}
+	return found
+}
@@ -0,0 +1,14 @@
+// Package baseline 用于测试 -baseline，fn1 中的问题已记录在基线中，不再报告
+package baseline
+
+func fn1() {
+	go func() {}()
+	go func() {}()
+	// 问题描述中有函数的位置
+	f := func() {}
+	go f()
+}
+
+func fn2() {
+	go func() {}() // want "goroutine not recovered"
+}
@@ -0,0 +1,14 @@
+package demo
+
+// 先调用已 recover 的函数
+func fn10() {
+	go func() {
+		fn11()
+	}()
+}
+
+func fn11() { // want fn11:"recovers at .*1.go:12:7"
+	defer func() {
+		_ = recover() // want "recover\\(\\) swallows the panic"
+	}()
+}
@@ -0,0 +1,40 @@
+package demo
+
+// recover() 不在 defer 的函数中，没有效果
+func fn20() {
+	go func() { // want "goroutine not recovered, func type is \\*ast.FuncLit"
+		_ = recover() // want "recover\\(\\) not called by a deferred func, has no effect"
+	}()
+}
+
+func fn21() {
+	go func() { // want "goroutine not recovered"
+		recover() // want "recover\\(\\) not called by a deferred func, has no effect"
+	}()
+}
+
+func fn22() {
+	go func() { // want "goroutine not recovered"
+		if re := recover(); re != nil { // want "recover\\(\\) not called by a deferred func, has no effect"
+		}
+	}()
+}
+
+// 带括号的 defer 函数和不带括号的一样检查
+func fn23() {
+	go func() {
+		defer (func() {
+			_ = recover() // want "recover\\(\\) swallows the panic"
+		})()
+	}()
+}
+
+func fn24() {
+	go func() {
+		defer (func() {
+			if re := recover(); re != nil {
+				println(re)
+			}
+		})()
+	}()
+}
@@ -0,0 +1,22 @@
+package demo
+
+// 函数参数中的函数，由已 recover 的 fn30 运行
+func fn30(fn func()) { // want fn30:"recovers at .*3.go:6:7"
+	defer func() {
+		_ = recover() // want "recover\\(\\) swallows the panic"
+	}()
+	fn()
+}
+
+func fn31() {
+	go fn30(fn32)
+}
+
+func fn32() {
+}
+
+func fn33() {
+	go fn30(func() {
+		fn31()
+	})
+}
@@ -0,0 +1,192 @@
+package demo
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+func recovered() { // want recovered:"recovers at .*5.go:11:11"
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println(r)
+		}
+	}()
+}
+
+func notRecovered() {
+}
+
+// 通过 recovered 间接 recover
+func viaRecovered() { // want viaRecovered:"recovers via demo.recovered at .*5.go:11:11"
+	recovered()
+}
+
+// 直接调用 recover() 的函数，可以用于 defer
+func recoverHelper() { // want recoverHelper:"calls recover at .*5.go:27:10"
+	if r := recover(); r != nil {
+		log.Println(r)
+	}
+}
+
+func deferHelper() { // want deferHelper:"recovers at .*5.go:27:10"
+	defer recoverHelper()
+}
+
+// 函数的值
+func fn50() {
+	go recovered()
+	go viaRecovered()
+	go deferHelper()
+	go notRecovered()   // want "goroutine not recovered, func type is \\*ast.Ident, demo.notRecovered not recovered"
+	go (notRecovered)() // want "demo.notRecovered not recovered"
+}
+
+// func 字面量
+func fn51() {
+	go func() {
+		defer recoverHelper()
+	}()
+	go func() { // want "goroutine not recovered, func type is \\*ast.FuncLit, call panic after demo.recovered"
+		recovered()
+		panic("ok")
+	}()
+	go func() { // want "call notRecovered after demo.viaRecovered"
+		viaRecovered()
+		notRecovered()
+	}()
+	go func() {
+		var n int
+		viaRecovered()
+		n++
+		_ = n
+	}()
+	go func() { // want "goroutine not recovered, func type is \\*ast.FuncLit, demo.notRecovered not recovered"
+		notRecovered()
+		recovered()
+	}()
+	go func() { // want "goroutine not recovered, func type is \\*ast.FuncLit, call factoryBad before recover"
+		_ = factoryBad()
+		recovered()
+	}()
+	go func() { // want "goroutine not recovered, func type is \\*ast.FuncLit$"
+		println()
+	}()
+}
+
+type worker struct{}
+
+func (worker) Good() { // want Good:"recovers via demo.recovered"
+	recovered()
+}
+
+func (*worker) Bad() {
+}
+
+type runner interface {
+	Run()
+}
+
+// 方法、接口方法
+func fn52(w *worker, r runner) {
+	go w.Good()
+	go w.Bad() // want "goroutine not recovered, func type is \\*ast.SelectorExpr, \\(\\*demo.worker\\).Bad not recovered"
+	go r.Run() // want "goroutine not checked, interface method \\(demo.runner\\).Run can't be determined statically"
+}
+
+// 局部变量检查赋值给它的所有函数，参数、map 中的函数等静态无法确定，以 info 级别报告
+func fn53(fn func(), fns map[string]func()) {
+	f := notRecovered
+	go f()        // want "goroutine not recovered, func type is \\*ast.Ident, demo.notRecovered not recovered"
+	go fn()       // want "goroutine not checked, func value fn can't be determined statically"
+	go fns["a"]() // want "goroutine not checked, dynamic func fns\\[\"a\"\\] can't be determined statically"
+
+	g := func() {
+		defer recoverHelper()
+	}
+	go g()
+	if fn != nil {
+		g = viaRecovered
+	}
+	go g()
+
+	h := recovered
+	h = func() {
+		notRecovered()
+	}
+	go h() // want "possible func literal at .*5.go:[0-9]+:6: demo.notRecovered not recovered"
+
+	p := factoryGood()
+	go p() // want "goroutine not checked, func value p can't be determined statically"
+}
+
+func factoryGood() func() { // want factoryGood:"returns recovered"
+	return func() {
+		recovered()
+	}
+}
+
+func factoryBad() func() {
+	return func() {}
+}
+
+// 返回函数的函数
+func fn54(fs []func() func()) {
+	go factoryGood()()
+	go factoryBad()() // want "goroutine not recovered, func type is \\*ast.CallExpr"
+	go fs[0]()()      // want "goroutine not checked, dynamic factory fs\\[0\\] can't be determined statically"
+}
+
+// 会启动 goroutine 的函数，以及 sync.OnceFunc 这类会调用参数的函数
+func fn55() {
+	time.AfterFunc(time.Second, recovered)
+	time.AfterFunc(time.Second, func() {}) // want "launched by time.AfterFunc"
+	go sync.OnceFunc(recovered)()
+	go sync.OnceFunc(func() {})() // want "goroutine not recovered, func type is \\*ast.FuncLit"
+}
+
+func fn56() {
+	//gorecover:ignore 不会 panic
+	go notRecovered()
+}
+
+// 调用已 recover 的函数之后，又调用了其他函数，未 recover，没有 fact
+func callAfterRecovered() {
+	recovered()
+	notRecovered()
+}
+
+func fn57() {
+	go callAfterRecovered() // want "demo.callAfterRecovered not recovered"
+}
+
+// 返回函数、局部变量的 factory
+func factoryFunc() func() { // want factoryFunc:"returns recovered"
+	return recovered
+}
+
+func factoryFuncBad() func() {
+	return notRecovered
+}
+
+func factoryVar() func() { // want factoryVar:"returns recovered"
+	f := recovered
+	return f
+}
+
+func factoryParam(fn func()) func() {
+	return fn
+}
+
+func fn58() {
+	go factoryFunc()()
+	go factoryFuncBad()() // want "goroutine not recovered, func type is \\*ast.CallExpr"
+	go factoryVar()()
+	go factoryParam(recovered)() // want "goroutine not recovered, func type is \\*ast.CallExpr"
+}
+
+// 行尾的 //gorecover:ignore 只忽略同一行的语句
+func fn59() {
+	go notRecovered() //gorecover:ignore 不会 panic
+	go notRecovered() // want "demo.notRecovered not recovered"
+}
@@ -0,0 +1,50 @@
+package demo
+
+import "wrapper"
+
+// 跨 package 的封装函数
+func fn60(fn func()) {
+	go wrapper.Go(fn)
+	go wrapper.Safe(fn)
+	go wrapper.Run(fn) // want "goroutine not recovered, func type is \\*ast.SelectorExpr, wrapper.Run not recovered"
+	go wrapper.Task(fn)()
+	go func() {
+		defer wrapper.Recover()
+		fn()
+	}()
+	go func() {
+		wrapper.Go(fn)
+	}()
+	go func() { // want "call fn after wrapper.Safe"
+		wrapper.Safe(fn)
+		fn()
+	}()
+}
+
+// wrapper.Safe 之后的代码未 recover，没有 fact
+func localGoBad(fn func()) {
+	wrapper.Safe(fn)
+	fn()
+}
+
+func fn63(fn func()) {
+	go localGoBad(fn) // want "demo.localGoBad not recovered"
+}
+
+// 通过其他 package 的函数 recover 的本地封装函数
+func localGo(fn func()) { // want localGo:"recovers via wrapper.Safe -> wrapper.Go at .*wrapper.go:9:11"
+	wrapper.Safe(fn)
+}
+
+func fn61() {
+	go localGo(notRecovered)
+}
+
+// defer 其他 package 中直接调用 recover() 的函数
+func localDefer() { // want localDefer:"recovers at .*wrapper.go:28:10"
+	defer wrapper.Recover()
+}
+
+func fn62() {
+	go localDefer()
+}
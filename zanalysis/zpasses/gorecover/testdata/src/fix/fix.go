@@ -0,0 +1,62 @@
+// Package fix 用于测试生成的修复，修复后的代码见 .golden 文件
+package fix
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+type S struct{}
+
+func (s S) Run(n int) {
+	log.Println(n)
+}
+
+func work(n int) {
+	log.Println(n)
+}
+
+func sum(prefix string, nums ...int) {
+	log.Println(prefix, nums)
+}
+
+func task() error {
+	return fmt.Errorf("failed")
+}
+
+func load(name string) (int, error) {
+	return len(name), nil
+}
+
+func tick() {}
+
+func fn1(s S, nums []int) {
+	go func() { // want "goroutine not recovered"
+		work(1)
+	}()
+	go func() { work(2) }()           // want "goroutine not recovered"
+	go work(3)                        // want "goroutine not recovered"
+	go s.Run(4)                       // want "goroutine not recovered"
+	go sum("a", nums...)              // want "goroutine not recovered"
+	go sum("b", 1, 2)                 // want "goroutine not recovered"
+	time.AfterFunc(time.Second, tick) // want "goroutine not recovered"
+}
+
+func fn2(eg *errgroup.Group) error {
+	eg.Go(task)                           // want "goroutine not recovered"
+	eg.Go(func() error { return task() }) // want "goroutine not recovered"
+	var err error
+	eg.Go(func() error { // want "goroutine not recovered"
+		_, err = load("a")
+		return err
+	})
+	eg.Go(func() (e error) { // want "goroutine not recovered"
+		_, e = load("b")
+		return
+	})
+	go func() (int, error) { return load("c") }() // want "goroutine not recovered"
+	return eg.Wait()
+}
@@ -0,0 +1,9 @@
+package fix
+
+import (
+	"golang.org/x/sync/errgroup"
+)
+
+func fn5(eg *errgroup.Group) {
+	eg.Go(task) // want "goroutine not recovered"
+}
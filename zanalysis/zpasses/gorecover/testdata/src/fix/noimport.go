@@ -0,0 +1,5 @@
+package fix
+
+func fn3() {
+	go func() { tick() }() // want "goroutine not recovered"
+}
@@ -0,0 +1,9 @@
+package fix
+
+import "time"
+
+func fn4() {
+	time.AfterFunc(time.Second, func() { // want "goroutine not recovered"
+		tick()
+	})
+}
@@ -0,0 +1,46 @@
+package generic
+
+import "log"
+
+// Run 已 recover 的泛型函数
+func Run[T any](v T, fn func(T)) { // want Run:"recovers at .*generic.go:8:11"
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println(r)
+		}
+	}()
+	fn(v)
+}
+
+// Bad 未 recover 的泛型函数
+func Bad[T any](v T, fn func(T)) {
+	fn(v)
+}
+
+// Pool 泛型类型
+type Pool[T any] struct {
+	items []T
+}
+
+func (p *Pool[T]) Go(fn func(T)) { // want Go:"recovers via generic.Run at .*generic.go:8:11"
+	Run(p.items[0], fn)
+}
+
+func (p *Pool[T]) Bad(fn func(T)) {
+	fn(p.items[0])
+}
+
+func fn() {
+	f := func(int) {}
+	go Run(1, f)
+	go Run[int](1, f)
+	go Bad(1, f)            // want "goroutine not recovered, func type is \\*ast.Ident, generic.Bad not recovered"
+	go Bad[string]("", nil) // want "goroutine not recovered, func type is \\*ast.IndexExpr, generic.Bad not recovered"
+
+	p := &Pool[int]{}
+	go p.Go(f)
+	go p.Bad(f) // want "\\(\\*generic.Pool\\[T\\]\\).Bad not recovered"
+	go func() {
+		Run(1, f)
+	}()
+}
@@ -0,0 +1,14 @@
+// Package errgroup golang.org/x/sync/errgroup 的简化版本，用于测试 launcher
+package errgroup
+
+type Group struct{}
+
+func (g *Group) Go(f func() error) {
+	go func() {
+		_ = f()
+	}()
+}
+
+func (g *Group) Wait() error {
+	return nil
+}
@@ -0,0 +1,16 @@
+// Package inner 只被 ssadep 导入，ssamode 间接依赖
+package inner
+
+import "log"
+
+type Worker struct{}
+
+func (w *Worker) Run() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println(r)
+		}
+	}()
+}
+
+func (w *Worker) Stop() {}
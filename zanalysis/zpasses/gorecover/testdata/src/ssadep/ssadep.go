@@ -0,0 +1,8 @@
+// Package ssadep 用于测试 -mode=ssa 时，通过间接依赖的 package 中的方法
+package ssadep
+
+import "ssadep/inner"
+
+func NewWorker() *inner.Worker {
+	return &inner.Worker{}
+}
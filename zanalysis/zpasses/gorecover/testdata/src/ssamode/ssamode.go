@@ -0,0 +1,80 @@
+// Package ssamode 用于测试 -mode=ssa，AST 无法确定的函数变量，使用 SSA 查找可能的函数
+package ssamode
+
+import (
+	"log"
+
+	"ssadep"
+)
+
+func recovered() { // want recovered:"recovers at .*ssamode.go:12:11"
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println(r)
+		}
+	}()
+}
+
+func notRecovered() {}
+
+func good() func() { // want good:"returns recovered"
+	return recovered
+}
+
+func bad() func() {
+	return notRecovered
+}
+
+var (
+	global    = recovered
+	globalBad = notRecovered
+)
+
+type task struct {
+	run func()
+}
+
+func fn1(fn func(), t task) {
+	p := good()
+	go p()
+	q := bad()
+	go q() // want "goroutine not recovered"
+	go global()
+	go globalBad() // want "goroutine not recovered"
+
+	// 参数、struct 字段依旧无法确定
+	go fn()    // want "goroutine not checked, func value fn can't be determined statically"
+	go t.run() // want "goroutine not checked"
+}
+
+// fn2 可能的函数中有参数，无法确定，不能认为已 recover
+func fn2(c bool, param func()) {
+	var h func()
+	if c {
+		h = param
+	} else {
+		h = recovered
+	}
+	go h() // want "goroutine not checked, func value h can.t be determined statically"
+
+	m := recovered
+	go func() { // want "goroutine not recovered"
+		m = param
+	}()
+	go m() // want "goroutine not checked, func value m can.t be determined statically"
+
+	var ok func()
+	if c {
+		ok = recovered
+	}
+	go ok()
+}
+
+// fn3 方法属于间接依赖的 package
+func fn3() {
+	w := ssadep.NewWorker()
+	run := w.Run
+	go run()
+	stop := w.Stop
+	go stop() // want "goroutine not recovered"
+}
@@ -0,0 +1,6 @@
+// Package report 用于测试 -tests=report
+package report
+
+func fn1() {
+	go func() {}() // want "goroutine not recovered"
+}
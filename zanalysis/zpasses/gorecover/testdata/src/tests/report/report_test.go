@@ -0,0 +1,5 @@
+package report
+
+func fn2() {
+	go func() {}() // want `^\[[0-9]+\] goroutine not recovered`
+}
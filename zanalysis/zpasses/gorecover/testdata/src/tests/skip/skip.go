@@ -0,0 +1,6 @@
+// Package skip 用于测试 -tests=skip
+package skip
+
+func fn1() {
+	go func() {}() // want "goroutine not recovered"
+}
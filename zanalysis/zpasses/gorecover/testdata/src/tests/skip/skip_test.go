@@ -0,0 +1,5 @@
+package skip
+
+func fn2() {
+	go func() {}()
+}
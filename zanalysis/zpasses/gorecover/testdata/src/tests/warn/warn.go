@@ -0,0 +1,6 @@
+// Package warn 用于测试 -tests=warn
+package warn
+
+func fn1() {
+	go func() {}() // want "goroutine not recovered"
+}
@@ -0,0 +1,5 @@
+package warn
+
+func fn2() {
+	go func() {}() // want `\[warning\] \[[0-9]+\] goroutine not recovered`
+}
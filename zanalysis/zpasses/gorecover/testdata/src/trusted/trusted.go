@@ -0,0 +1,15 @@
+// Package trusted 用于测试 -trusted-modules=std，标准库中的函数认为已 recover
+package trusted
+
+import (
+	"io"
+
+	"wrapper"
+)
+
+func fn1(w io.Writer, r io.Reader, fn func()) {
+	go io.Copy(w, r)
+	go wrapper.Go(fn)
+	// GOPATH 模式下没有 module 信息，不是可信的 module
+	go wrapper.Run(fn) // want "wrapper.Run not recovered"
+}
@@ -0,0 +1,38 @@
+// Package wrapper 被其他 package 使用的封装函数，用于测试跨 package 的 RecoversFact
+package wrapper
+
+import "log"
+
+// Go 已 recover，在 recover 之后运行 fn
+func Go(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println(r)
+		}
+	}()
+	fn()
+}
+
+// Safe 通过 Go 间接 recover
+func Safe(fn func()) {
+	Go(fn)
+}
+
+// Run 未 recover
+func Run(fn func()) {
+	fn()
+}
+
+// Recover 直接调用 recover()，用于 defer wrapper.Recover()
+func Recover() {
+	if r := recover(); r != nil {
+		log.Println(r)
+	}
+}
+
+// Task 返回的函数已 recover
+func Task(fn func()) func() {
+	return func() {
+		Go(fn)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package gorecover
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/fsgo/gocode/internal/asthelper"
+)
+
+// localFuncValues 返回赋值给局部变量 v 的所有值，用于 f := func(){}; go f()
+// v 不是通过赋值定义的(如参数、range 的变量)、被取了地址，或者有无法对应的赋值(如 a, b := pick())时，
+// 静态无法确定，返回 ok=false
+func localFuncValues(pass *analysis.Pass, v *types.Var) (values []ast.Expr, ok bool) {
+	if v.IsField() || v.Pkg() == nil || v.Parent() == v.Pkg().Scope() {
+		return nil, false
+	}
+	file := fileOf(pass, v.Pos())
+	if file == nil {
+		return nil, false
+	}
+	is := func(expr ast.Expr) bool {
+		id, ok := astutil.Unparen(expr).(*ast.Ident)
+		return ok && (pass.TypesInfo.Defs[id] == v || pass.TypesInfo.Uses[id] == v)
+	}
+	var defined, unknown bool
+	ast.Inspect(file, func(node ast.Node) bool {
+		if unknown {
+			return false
+		}
+		switch vt := node.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range vt.Lhs {
+				if !is(lhs) {
+					continue
+				}
+				if len(vt.Lhs) != len(vt.Rhs) {
+					unknown = true
+					return false
+				}
+				defined = defined || vt.Tok == token.DEFINE
+				values = append(values, vt.Rhs[i])
+			}
+		case *ast.ValueSpec:
+			for i, name := range vt.Names {
+				if pass.TypesInfo.Defs[name] != v {
+					continue
+				}
+				defined = true
+				if len(vt.Values) == 0 {
+					// var f func()，零值，调用时 panic
+					continue
+				}
+				if len(vt.Names) != len(vt.Values) {
+					unknown = true
+					return false
+				}
+				values = append(values, vt.Values[i])
+			}
+		case *ast.UnaryExpr:
+			if vt.Op == token.AND && is(vt.X) {
+				unknown = true
+				return false
+			}
+		}
+		return true
+	})
+	if unknown || !defined || len(values) == 0 {
+		return nil, false
+	}
+	return values, true
+}
+
+// funcValuesRecovered 判断 values 中的函数是否都已 recover，
+// 支持 func 字面量以及函数、方法，有未 recover 的函数时返回 known=true，
+// 否则有其他的值(如函数调用的结果)时，静态无法确定，返回 known=false
+func funcValuesRecovered(pass *analysis.Pass, values []ast.Expr, rc *recovery) (ok bool, reason string, known bool) {
+	first := &recovery{}
+	var unknown bool
+	for _, value := range values {
+		var rf *RecoversFact
+		var at ast.Node
+		switch vt := astutil.Unparen(value).(type) {
+		case *ast.FuncLit:
+			if rf, at, reason = bodyRecovered(pass, vt.Body, factResolver(pass)); rf == nil {
+				if reason == "" {
+					reason = "not recovered"
+				}
+				reason = fmt.Sprintf("possible func literal at %s: %s", asthelper.RelName(pass.Fset.Position(vt.Pos()).String()), reason)
+			}
+		default:
+			fn, isFunc := typeutil.Callee(pass.TypesInfo, &ast.CallExpr{Fun: value}).(*types.Func)
+			if !isFunc || isInterfaceMethod(fn) {
+				unknown = true
+				continue
+			}
+			rf, reason = chainTo(pass, fn, importFact(pass, fn))
+		}
+		if rf == nil {
+			return false, reason, true
+		}
+		if first.rf == nil {
+			first.set(rf, at)
+		}
+	}
+	if unknown {
+		return false, "", false
+	}
+	rc.set(first.rf, first.at)
+	return true, "", true
+}
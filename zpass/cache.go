@@ -0,0 +1,662 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/objectpath"
+)
+
+// CacheMode 结果缓存的使用方式，零值和 CacheOff 相同，可以作为 flag.Value 使用
+type CacheMode string
+
+const (
+	CacheOff       CacheMode = "off"       // 不使用缓存
+	CacheRead      CacheMode = "read"      // 只读取缓存，不写入
+	CacheReadWrite CacheMode = "readwrite" // 读取缓存，没有时执行并写入
+)
+
+func (m *CacheMode) String() string {
+	if *m == "" {
+		return string(CacheOff)
+	}
+	return string(*m)
+}
+
+func (m *CacheMode) Set(s string) error {
+	switch v := CacheMode(s); v {
+	case CacheOff, CacheRead, CacheReadWrite:
+		*m = v
+		return nil
+	default:
+		return fmt.Errorf("invalid cache mode %q, should be one of: off, read, readwrite", s)
+	}
+}
+
+func (m CacheMode) readable() bool {
+	return m == CacheRead || m == CacheReadWrite
+}
+
+var cacheMode CacheMode
+
+// RegisterCacheFlag 注册 -cache 参数，RegisterFlags 会调用，
+// 使用 singlechecker 等 driver 的程序在其解析参数前调用
+func RegisterCacheFlag() {
+	if flag.Lookup("cache") == nil {
+		flag.Var(&cacheMode, "cache", "result cache in "+CacheDir()+": off|read|readwrite\nunchanged packages replay their diagnostics, facts and outputs")
+	}
+}
+
+// SetCacheMode 设置缓存的使用方式，和 -cache 参数一致
+func SetCacheMode(m CacheMode) {
+	cacheMode = m
+}
+
+// CacheDir 返回缓存目录：$XDG_CACHE_HOME/zpass，没有设置时为 os.UserCacheDir() 下的 zpass
+func CacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "zpass")
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "zpass")
+}
+
+// CleanCache 删除所有的缓存
+func CleanCache() error {
+	return os.RemoveAll(CacheDir())
+}
+
+// CacheMain 处理 cache 子命令，args 为 cache 之后的参数，返回进程的退出码
+//
+//	cache clean  删除所有的缓存
+//	cache dir    输出缓存目录
+func CacheMain(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: cache clean|dir")
+		return 2
+	}
+	switch args[0] {
+	case "clean":
+		if err := CleanCache(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	case "dir":
+		fmt.Println(CacheDir())
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown cache command %q, should be one of: clean, dir\n", args[0])
+		return 2
+	}
+}
+
+// CacheOptions analyzer 的缓存选项
+type CacheOptions struct {
+	// Version analyzer 的版本，逻辑变化时修改，使旧的缓存失效
+	// 程序文件本身的 hash 也是缓存 key 的一部分，重新编译后缓存同样会失效
+	Version string
+
+	// Files 除了 package 的源文件、.zpass.yaml 之外，影响结果的文件，如 -config 指定的配置文件
+	Files func(pass *analysis.Pass) []string
+
+	// Disabled 返回 true 时不使用缓存，如执行有副作用时
+	Disabled func() bool
+}
+
+var cacheOptions sync.Map // *analysis.Analyzer -> *CacheOptions
+
+// SetCacheOptions 让 analyzer 使用缓存，一般在 analyzer 所在 package 的 init 中调用，
+// 只有调用了的 analyzer 才会缓存，重复调用时只更新选项
+//
+// 缓存的 key 包括 analyzer 的版本和参数、package 的源文件、依赖 package 的 API 和 fact，
+// 命中时不执行 Run，而是重放之前的诊断信息、导出的 fact、Println 的输出以及结果，
+// 所以 fact 和结果需要可以使用 gob 编码，无法编码时不写入缓存
+func SetCacheOptions(a *analysis.Analyzer, opts CacheOptions) {
+	if _, loaded := cacheOptions.Swap(a, &opts); !loaded {
+		a.Run = cachedRun(a, a.Run)
+	}
+}
+
+// Println 输出一行到 stdout，使用缓存时会记录下来，缓存命中时重放，
+// analyzer 的输出(如 go-doc-json 的结果)应使用它而不是 fmt.Println
+func Println(pass *analysis.Pass, a ...any) {
+	line := fmt.Sprintln(a...)
+	if v, ok := recorders.Load(pass); ok {
+		rec := v.(*recorder)
+		rec.mu.Lock()
+		rec.entry.Output = append(rec.entry.Output, line...)
+		rec.mu.Unlock()
+	}
+	_, _ = io.WriteString(os.Stdout, line)
+}
+
+// cacheEntry 缓存的一个 analyzer 在一个 package 上的执行结果
+type cacheEntry struct {
+	Files        []string // 位置所在的文件，cachedPos.File 为其下标
+	Diagnostics  []cachedDiagnostic
+	ObjectFacts  []cachedFact
+	PackageFacts []cachedFact
+	Output       []byte
+	Result       []byte // gob 编码的结果，结果为 nil 时为空
+}
+
+type cachedPos struct {
+	File   int // Files 中的下标，-1 表示 token.NoPos
+	Offset int
+}
+
+type cachedRelated struct {
+	Pos, End cachedPos
+	Message  string
+}
+
+type cachedEdit struct {
+	Pos, End cachedPos
+	NewText  []byte
+}
+
+type cachedFix struct {
+	Message   string
+	TextEdits []cachedEdit
+}
+
+type cachedDiagnostic struct {
+	Pos, End cachedPos
+	Category string
+	URL      string
+	Message  string
+	Related  []cachedRelated
+	Fixes    []cachedFix
+}
+
+type cachedFact struct {
+	Object string // objectpath，package fact 为空
+	Type   int    // Analyzer.FactTypes 中的下标
+	Data   []byte // gob 编码的 fact
+}
+
+// recorder 记录一次 Run 的诊断信息、fact 和输出
+type recorder struct {
+	mu    sync.Mutex
+	pass  *analysis.Pass
+	files map[string]int
+	entry cacheEntry
+	err   error // 无法缓存的原因
+}
+
+var recorders sync.Map // *analysis.Pass -> *recorder
+
+func cachedRun(a *analysis.Analyzer, run func(*analysis.Pass) (any, error)) func(*analysis.Pass) (any, error) {
+	return func(pass *analysis.Pass) (any, error) {
+		v, _ := cacheOptions.Load(a)
+		opts := v.(*CacheOptions)
+		if !cacheMode.readable() || (opts.Disabled != nil && opts.Disabled()) {
+			return run(pass)
+		}
+		key, err := cacheKey(pass, opts)
+		if err != nil {
+			if IsTrace() {
+				log.Printf("[%s] cache disabled for pkg %s: %v\n", a.Name, pass.Pkg.Path(), err)
+			}
+			return run(pass)
+		}
+		if entry, err := loadCacheEntry(key); err == nil {
+			result, err := entry.replay(pass)
+			if err == nil {
+				if IsTrace() {
+					log.Printf("[%s] cache hit for pkg %s\n", a.Name, pass.Pkg.Path())
+				}
+				return result, nil
+			}
+			if IsTrace() {
+				log.Printf("[%s] replay cache for pkg %s failed: %v\n", a.Name, pass.Pkg.Path(), err)
+			}
+		}
+
+		rec := &recorder{pass: pass, files: make(map[string]int)}
+		restore := rec.hook()
+		recorders.Store(pass, rec)
+		result, err := run(pass)
+		recorders.Delete(pass)
+		restore()
+		if err != nil || cacheMode != CacheReadWrite {
+			return result, err
+		}
+		if err1 := rec.save(key, result); err1 != nil && IsTrace() {
+			log.Printf("[%s] write cache for pkg %s failed: %v\n", a.Name, pass.Pkg.Path(), err1)
+		}
+		return result, err
+	}
+}
+
+// hook 替换 pass 的 Report、ExportObjectFact、ExportPackageFact 以记录，返回的函数用于恢复
+func (rec *recorder) hook() (restore func()) {
+	pass := rec.pass
+	report, exportObject, exportPackage := pass.Report, pass.ExportObjectFact, pass.ExportPackageFact
+	pass.Report = func(d analysis.Diagnostic) {
+		rec.addDiagnostic(d)
+		report(d)
+	}
+	pass.ExportObjectFact = func(obj types.Object, fact analysis.Fact) {
+		exportObject(obj, fact)
+		path, err := objectpath.For(obj)
+		if err != nil {
+			// 未导出的对象等没有 objectpath，其他 package 读取不到其 fact，
+			// 和 go vet 一样不保存
+			return
+		}
+		rec.addFact(&rec.entry.ObjectFacts, string(path), fact)
+	}
+	pass.ExportPackageFact = func(fact analysis.Fact) {
+		exportPackage(fact)
+		rec.addFact(&rec.entry.PackageFacts, "", fact)
+	}
+	return func() {
+		pass.Report, pass.ExportObjectFact, pass.ExportPackageFact = report, exportObject, exportPackage
+	}
+}
+
+func (rec *recorder) fail(err error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.err == nil {
+		rec.err = err
+	}
+}
+
+func (rec *recorder) addFact(facts *[]cachedFact, object string, fact analysis.Fact) {
+	idx := factTypeIndex(rec.pass.Analyzer.FactTypes, fact)
+	if idx < 0 {
+		rec.fail(fmt.Errorf("unknown fact type %T", fact))
+		return
+	}
+	bf := &bytes.Buffer{}
+	if err := gob.NewEncoder(bf).Encode(fact); err != nil {
+		rec.fail(fmt.Errorf("encode fact %T: %w", fact, err))
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	*facts = append(*facts, cachedFact{Object: object, Type: idx, Data: bf.Bytes()})
+}
+
+func factTypeIndex(facts []analysis.Fact, fact analysis.Fact) int {
+	for i, f := range facts {
+		if reflect.TypeOf(f) == reflect.TypeOf(fact) {
+			return i
+		}
+	}
+	return -1
+}
+
+// pos 将 token.Pos 转换为 文件+偏移量，调用时需持有锁
+func (rec *recorder) pos(p token.Pos) cachedPos {
+	tf := rec.pass.Fset.File(p)
+	if !p.IsValid() || tf == nil {
+		return cachedPos{File: -1}
+	}
+	idx, ok := rec.files[tf.Name()]
+	if !ok {
+		idx = len(rec.entry.Files)
+		rec.files[tf.Name()] = idx
+		rec.entry.Files = append(rec.entry.Files, tf.Name())
+	}
+	return cachedPos{File: idx, Offset: tf.Offset(p)}
+}
+
+func (rec *recorder) addDiagnostic(d analysis.Diagnostic) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	cd := cachedDiagnostic{
+		Pos:      rec.pos(d.Pos),
+		End:      rec.pos(d.End),
+		Category: d.Category,
+		URL:      d.URL,
+		Message:  d.Message,
+	}
+	for _, r := range d.Related {
+		cd.Related = append(cd.Related, cachedRelated{Pos: rec.pos(r.Pos), End: rec.pos(r.End), Message: r.Message})
+	}
+	for _, fix := range d.SuggestedFixes {
+		cf := cachedFix{Message: fix.Message}
+		for _, edit := range fix.TextEdits {
+			cf.TextEdits = append(cf.TextEdits, cachedEdit{Pos: rec.pos(edit.Pos), End: rec.pos(edit.End), NewText: edit.NewText})
+		}
+		cd.Fixes = append(cd.Fixes, cf)
+	}
+	rec.entry.Diagnostics = append(rec.entry.Diagnostics, cd)
+}
+
+// save 写入缓存，有无法缓存的 fact 或者结果时不写入
+func (rec *recorder) save(key string, result any) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.err != nil {
+		return rec.err
+	}
+	if result != nil && !isNilPointer(result) {
+		bf := &bytes.Buffer{}
+		if err := gob.NewEncoder(bf).Encode(result); err != nil {
+			return fmt.Errorf("encode result %T: %w", result, err)
+		}
+		rec.entry.Result = bf.Bytes()
+	}
+	bf := &bytes.Buffer{}
+	if err := gob.NewEncoder(bf).Encode(&rec.entry); err != nil {
+		return err
+	}
+	name := cacheFile(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(bf.Bytes())
+	if err1 := tmp.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+func cacheFile(key string) string {
+	return filepath.Join(CacheDir(), key[:2], key)
+}
+
+func loadCacheEntry(key string) (*cacheEntry, error) {
+	bf, err := os.ReadFile(cacheFile(key))
+	if err != nil {
+		return nil, err
+	}
+	entry := &cacheEntry{}
+	if err = gob.NewDecoder(bytes.NewReader(bf)).Decode(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// replay 重放缓存的执行结果，先全部解码，解码失败时不会有任何副作用
+func (entry *cacheEntry) replay(pass *analysis.Pass) (any, error) {
+	files := make([]*token.File, len(entry.Files))
+	for _, f := range pass.Files {
+		tf := pass.Fset.File(f.Pos())
+		for i, name := range entry.Files {
+			if tf != nil && tf.Name() == name {
+				files[i] = tf
+			}
+		}
+	}
+	var err error
+	pos := func(cp cachedPos) token.Pos {
+		if cp.File < 0 {
+			return token.NoPos
+		}
+		tf := files[cp.File]
+		if tf == nil || cp.Offset > tf.Size() {
+			err = fmt.Errorf("file %s not found in package", entry.Files[cp.File])
+			return token.NoPos
+		}
+		return tf.Pos(cp.Offset)
+	}
+
+	var ds []analysis.Diagnostic
+	for _, cd := range entry.Diagnostics {
+		d := analysis.Diagnostic{
+			Pos:      pos(cd.Pos),
+			End:      pos(cd.End),
+			Category: cd.Category,
+			URL:      cd.URL,
+			Message:  cd.Message,
+		}
+		for _, r := range cd.Related {
+			d.Related = append(d.Related, analysis.RelatedInformation{Pos: pos(r.Pos), End: pos(r.End), Message: r.Message})
+		}
+		for _, cf := range cd.Fixes {
+			fix := analysis.SuggestedFix{Message: cf.Message}
+			for _, edit := range cf.TextEdits {
+				fix.TextEdits = append(fix.TextEdits, analysis.TextEdit{Pos: pos(edit.Pos), End: pos(edit.End), NewText: edit.NewText})
+			}
+			d.SuggestedFixes = append(d.SuggestedFixes, fix)
+		}
+		ds = append(ds, d)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decodeFact := func(cf cachedFact) (analysis.Fact, error) {
+		if cf.Type < 0 || cf.Type >= len(pass.Analyzer.FactTypes) {
+			return nil, fmt.Errorf("invalid fact type %d", cf.Type)
+		}
+		fact := reflect.New(reflect.TypeOf(pass.Analyzer.FactTypes[cf.Type]).Elem()).Interface().(analysis.Fact)
+		if err := gob.NewDecoder(bytes.NewReader(cf.Data)).Decode(fact); err != nil {
+			return nil, err
+		}
+		return fact, nil
+	}
+	type objectFact struct {
+		obj  types.Object
+		fact analysis.Fact
+	}
+	var objectFacts []objectFact
+	for _, cf := range entry.ObjectFacts {
+		obj, err := objectpath.Object(pass.Pkg, objectpath.Path(cf.Object))
+		if err != nil {
+			return nil, err
+		}
+		fact, err := decodeFact(cf)
+		if err != nil {
+			return nil, err
+		}
+		objectFacts = append(objectFacts, objectFact{obj: obj, fact: fact})
+	}
+	var packageFacts []analysis.Fact
+	for _, cf := range entry.PackageFacts {
+		fact, err := decodeFact(cf)
+		if err != nil {
+			return nil, err
+		}
+		packageFacts = append(packageFacts, fact)
+	}
+
+	var result any
+	if len(entry.Result) > 0 {
+		rt := pass.Analyzer.ResultType
+		if rt == nil {
+			return nil, errors.New("analyzer has no result type")
+		}
+		rv := reflect.New(rt)
+		if err = gob.NewDecoder(bytes.NewReader(entry.Result)).Decode(rv.Interface()); err != nil {
+			return nil, err
+		}
+		result = rv.Elem().Interface()
+	} else if rt := pass.Analyzer.ResultType; rt != nil {
+		result = reflect.Zero(rt).Interface()
+	}
+
+	for _, of := range objectFacts {
+		pass.ExportObjectFact(of.obj, of.fact)
+	}
+	for _, fact := range packageFacts {
+		pass.ExportPackageFact(fact)
+	}
+	for _, d := range ds {
+		pass.Report(d)
+	}
+	if len(entry.Output) > 0 {
+		_, _ = os.Stdout.Write(entry.Output)
+	}
+	return result, nil
+}
+
+// executableHash 当前程序文件的 hash，程序重新编译后缓存失效
+var executableHash = sync.OnceValues(func() (string, error) {
+	name, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return fileHash(name)
+})
+
+func fileHash(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cacheKey 计算 analyzer 在 pass 所在 package 上的缓存 key
+func cacheKey(pass *analysis.Pass, opts *CacheOptions) (string, error) {
+	exe, err := executableHash()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "zpass cache v1\nexe %s\nanalyzer %s %s\npackage %s\n", exe, pass.Analyzer.Name, opts.Version, pass.Pkg.Path())
+	pass.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(h, "flag %s=%s\n", f.Name, f.Value)
+	})
+	fmt.Fprintf(h, "filter %s\n", DefaultFilter.key())
+	fmt.Fprintf(h, "path-style %s\n", pathStyle.key())
+
+	files := make([]string, 0, len(pass.Files)+len(pass.OtherFiles)+1)
+	for _, f := range pass.Files {
+		if tf := pass.Fset.File(f.Pos()); tf != nil {
+			files = append(files, tf.Name())
+		}
+	}
+	files = append(files, pass.OtherFiles...)
+	files = append(files, FindConfigFile(PackageDir(pass)))
+	if opts.Files != nil {
+		files = append(files, opts.Files(pass)...)
+	}
+	for _, name := range files {
+		if name == "" {
+			continue
+		}
+		fh, err := fileHash(name)
+		if errors.Is(err, os.ErrNotExist) {
+			fh = "missing"
+		} else if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "file %s %s\n", name, fh)
+	}
+
+	for _, imp := range pass.Pkg.Imports() {
+		fmt.Fprintf(h, "import %s %s\n", imp.Path(), apiHash(imp))
+	}
+	if err = hashFacts(h, pass); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+var apiHashes sync.Map // *types.Package -> string
+
+// apiHash 返回 package 的 API 的 hash，包括所有顶层对象及其类型、常量的值，
+// 依赖的 package 变化时，使用它的 package 的缓存失效
+func apiHash(pkg *types.Package) string {
+	if v, ok := apiHashes.Load(pkg); ok {
+		return v.(string)
+	}
+	h := sha256.New()
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		fmt.Fprintln(h, types.ObjectString(obj, nil))
+		switch vt := obj.(type) {
+		case *types.Const:
+			fmt.Fprintln(h, vt.Val().ExactString())
+		case *types.TypeName:
+			if named, ok := vt.Type().(*types.Named); ok {
+				for i := 0; i < named.NumMethods(); i++ {
+					fmt.Fprintln(h, types.ObjectString(named.Method(i), nil))
+				}
+			}
+		}
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	apiHashes.Store(pkg, sum)
+	return sum
+}
+
+// hashFacts 将依赖 package 的 fact 写入 h，fact 使用 json 编码，以保证顺序稳定
+func hashFacts(w io.Writer, pass *analysis.Pass) error {
+	var lines []string
+	add := func(prefix string, fact analysis.Fact) error {
+		bf, err := json.Marshal(fact)
+		if err != nil {
+			return fmt.Errorf("encode fact %T: %w", fact, err)
+		}
+		lines = append(lines, fmt.Sprintf("%s %T %s", prefix, fact, bf))
+		return nil
+	}
+	for _, pf := range pass.AllPackageFacts() {
+		if pf.Package == pass.Pkg {
+			continue
+		}
+		if err := add("package "+pf.Package.Path(), pf.Fact); err != nil {
+			return err
+		}
+	}
+	for _, of := range pass.AllObjectFacts() {
+		if of.Object.Pkg() == pass.Pkg {
+			continue
+		}
+		name := of.Object.String()
+		if path, err := objectpath.For(of.Object); err == nil {
+			name = of.Object.Pkg().Path() + " " + string(path)
+		}
+		if err := add("object "+name, of.Fact); err != nil {
+			return err
+		}
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"go/ast"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+type demoFact struct {
+	Name string
+}
+
+func (*demoFact) AFact() {}
+
+func (f *demoFact) String() string {
+	return f.Name
+}
+
+// captureStdout 将 fn 中输出到 os.Stdout 的内容返回
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = f
+	defer func() {
+		os.Stdout = stdout
+		_ = f.Close()
+	}()
+	fn()
+	bf, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(bf)
+}
+
+func TestCacheReplay(t *testing.T) {
+	// XDG_CACHE_HOME 也影响 go 命令默认的 GOCACHE，保持不变以免 analysistest 加载 package 时重新编译
+	if os.Getenv("GOCACHE") == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			t.Setenv("GOCACHE", filepath.Join(dir, "go-build"))
+		}
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	mode := cacheMode
+	SetCacheMode(CacheReadWrite)
+	t.Cleanup(func() {
+		SetCacheMode(mode)
+	})
+
+	var runs int
+	a := &analysis.Analyzer{
+		Name:       "zpass_cache_demo",
+		Doc:        "cache demo",
+		FactTypes:  []analysis.Fact{new(demoFact)},
+		ResultType: reflect.TypeOf(0),
+		Run: func(pass *analysis.Pass) (any, error) {
+			runs++
+			var n int
+			for _, f := range pass.Files {
+				for _, decl := range f.Decls {
+					fd, ok := decl.(*ast.FuncDecl)
+					if !ok {
+						continue
+					}
+					n++
+					obj := pass.TypesInfo.Defs[fd.Name]
+					if obj.Exported() {
+						pass.ExportObjectFact(obj, &demoFact{Name: "cached"})
+					}
+					pass.Report(analysis.Diagnostic{
+						Pos:      fd.Name.Pos(),
+						End:      fd.Name.End(),
+						Category: "zpass_cache_demo",
+						Message:  "func " + fd.Name.Name,
+						Related:  []analysis.RelatedInformation{{Pos: fd.Pos(), Message: "decl"}},
+						SuggestedFixes: []analysis.SuggestedFix{{
+							Message:   "rename",
+							TextEdits: []analysis.TextEdit{{Pos: fd.Name.Pos(), End: fd.Name.Pos(), NewText: []byte("New")}},
+						}},
+					})
+					Println(pass, "func", fd.Name.Name)
+				}
+			}
+			return n, nil
+		},
+	}
+	SetCacheOptions(a, CacheOptions{Version: "1"})
+
+	run := func() (result any, output string) {
+		output = captureStdout(t, func() {
+			rs := analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "cached")
+			if len(rs) != 1 {
+				t.Fatalf("got %d results, want 1", len(rs))
+			}
+			result = rs[0].Result
+		})
+		return result, output
+	}
+
+	result, output := run()
+	if runs != 1 {
+		t.Fatalf("first run: got %d runs, want 1", runs)
+	}
+	files, _ := filepath.Glob(filepath.Join(CacheDir(), "*", "*"))
+	if len(files) != 1 {
+		t.Fatalf("got cache files %v, want 1", files)
+	}
+
+	// 缓存命中时不执行 Run，诊断信息、fact、输出和结果都一样
+	cachedResult, cachedOutput := run()
+	if runs != 1 {
+		t.Fatalf("second run: got %d runs, want 1 (cache hit)", runs)
+	}
+	if cachedResult != result || result != 3 {
+		t.Errorf("got cached result %v, want %v", cachedResult, result)
+	}
+	want := "func A\nfunc B\nfunc c\n"
+	if output != want || cachedOutput != want {
+		t.Errorf("got output %q and cached %q, want %q", output, cachedOutput, want)
+	}
+
+	// 只读时不写入，参数变化时缓存失效
+	SetCacheMode(CacheRead)
+	a.Flags.String("demo", "", "")
+	run()
+	if runs != 2 {
+		t.Fatalf("flag changed: got %d runs, want 2", runs)
+	}
+	files, _ = filepath.Glob(filepath.Join(CacheDir(), "*", "*"))
+	if len(files) != 1 {
+		t.Fatalf("read mode: got cache files %v, want 1", files)
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileNames 配置文件的名字，从 package 所在目录开始向上查找，使用找到的第一个
+//
+// 配置文件中每个 analyzer 一个配置，key 为 analyzer 的名字(全名或者 ShortName)，如：
+//
+//	go_recover:
+//	  safe_funcs:
+//	    - github.com/sourcegraph/conc.(*WaitGroup).Go
+//	  max_depth: 5
+//
+// json 格式的内容也是合法的 yaml，所以使用相同的方式解析
+var ConfigFileNames = []string{".zpass.yaml", ".zpass.yml", ".zpass.json"}
+
+// configFile 解析后的配置文件
+type configFile struct {
+	path     string
+	sections map[string]yaml.Node
+}
+
+var (
+	// configDirs 目录 -> 其对应的配置文件，没有时为空字符串
+	configDirs sync.Map
+
+	// configFiles 配置文件路径 -> *configFile
+	configFiles sync.Map
+)
+
+// FindConfigFile 从目录 dir 开始向上查找配置文件，没有找到时返回空字符串
+func FindConfigFile(dir string) string {
+	if v, ok := configDirs.Load(dir); ok {
+		return v.(string)
+	}
+	var found string
+	for _, name := range ConfigFileNames {
+		fp := filepath.Join(dir, name)
+		if info, err := os.Stat(fp); err == nil && !info.IsDir() {
+			found = fp
+			break
+		}
+	}
+	if found == "" {
+		if parent := filepath.Dir(dir); parent != dir {
+			found = FindConfigFile(parent)
+		}
+	}
+	configDirs.Store(dir, found)
+	return found
+}
+
+func readConfigFile(name string) (*configFile, error) {
+	if v, ok := configFiles.Load(name); ok {
+		return v.(*configFile), nil
+	}
+	bf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	cf := &configFile{path: name}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(bf, &doc); err != nil {
+		return nil, fmt.Errorf("parser %s failed: %w", name, err)
+	}
+	if cf.sections, err = parseSections(name, &doc, Analyzers()); err != nil {
+		return nil, fmt.Errorf("%s:%w", name, err)
+	}
+	v, _ := configFiles.LoadOrStore(name, cf)
+	return v.(*configFile), nil
+}
+
+// parseSections 返回配置文件中每个 analyzer 的配置
+//
+// 配置文件是所有程序共用的，其中可能有当前程序没有的 analyzer(如只有 cmd/zpass 有的)，
+// 所以不是 analyzers 中的 key 会被忽略，和其中的某个名字相近(如拼写错误)时才返回错误
+func parseSections(name string, doc *yaml.Node, analyzers []*analysis.Analyzer) (map[string]yaml.Node, error) {
+	sections := make(map[string]yaml.Node)
+	if doc.Kind == 0 {
+		// 空文件
+		return sections, nil
+	}
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%d: want a mapping of analyzer name to its config", root.Line)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if FindAnalyzer(analyzers, key.Value) != nil {
+			sections[key.Value] = *root.Content[i+1]
+			continue
+		}
+		if a := similarAnalyzer(analyzers, key.Value); a != nil {
+			return nil, fmt.Errorf("%d: unknown analyzer %q, did you mean %q", key.Line, key.Value, ShortName(a))
+		}
+		if IsDebugVerbose() {
+			log.Printf("%s:%d: skip config of analyzer %q, which is not in this program\n", name, key.Line, key.Value)
+		}
+	}
+	return sections, nil
+}
+
+// similarAnalyzer 返回名字和 name 相近的 analyzer，编辑距离不超过 2，没有时返回 nil
+func similarAnalyzer(analyzers []*analysis.Analyzer, name string) *analysis.Analyzer {
+	for _, a := range analyzers {
+		if editDistance(name, a.Name) <= 2 || editDistance(name, ShortName(a)) <= 2 {
+			return a
+		}
+	}
+	return nil
+}
+
+// editDistance 返回 a、b 之间的编辑距离(Levenshtein)
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// section 返回 analyzer 的配置，可以使用全名或者 ShortName
+func (cf *configFile) section(a *analysis.Analyzer) (*yaml.Node, bool) {
+	if node, ok := cf.sections[a.Name]; ok {
+		return &node, true
+	}
+	if node, ok := cf.sections[ShortName(a)]; ok {
+		return &node, true
+	}
+	return nil, false
+}
+
+// LoadConfig 读取 pass 所在 package 对应的配置文件中，pass.Analyzer 的配置，
+// v 为配置 struct 的指针，使用 yaml tag 指定字段名，返回使用的配置文件，没有时为空字符串
+//
+// 带有 flag tag 的字段和 analyzer 的参数绑定，如 `yaml:"max_depth" flag:"max-depth"`：
+//   - 使用参数的值(默认值)初始化
+//   - 配置文件中的值覆盖参数的默认值
+//   - 命令行中明确指定的参数覆盖配置文件中的值
+//
+// 配置中有 v 没有的字段时返回错误，其他 analyzer 的配置不检查
+func LoadConfig(pass *analysis.Pass, v any) (string, error) {
+	if err := BindFlags(pass.Analyzer, v, false); err != nil {
+		return "", err
+	}
+	name := FindConfigFile(PackageDir(pass))
+	if name != "" {
+		if err := DecodeConfig(name, pass.Analyzer, v); err != nil {
+			return "", err
+		}
+	}
+	return name, BindFlags(pass.Analyzer, v, true)
+}
+
+// DecodeConfig 读取配置文件 name 中 analyzer 的配置到 v，配置中有 v 没有的字段时返回错误
+func DecodeConfig(name string, a *analysis.Analyzer, v any) error {
+	cf, err := readConfigFile(name)
+	if err != nil {
+		return err
+	}
+	node, ok := cf.section(a)
+	if !ok {
+		return nil
+	}
+	if err = checkKnownKeys(node, v); err != nil {
+		return fmt.Errorf("%s:%w", name, err)
+	}
+	if err = node.Decode(v); err != nil {
+		return fmt.Errorf("%s: %s: %w", name, ShortName(a), err)
+	}
+	return nil
+}
+
+// checkKnownKeys 检查配置中的 key 是否都是 v 中的字段，v 为 struct 指针
+func checkKnownKeys(node *yaml.Node, v any) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	known := make(map[string]bool)
+	rt := reflect.TypeOf(v).Elem()
+	for i := 0; i < rt.NumField(); i++ {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("yaml"), ",")
+		if name == "" {
+			name = strings.ToLower(rt.Field(i).Name)
+		}
+		known[name] = true
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !known[key.Value] {
+			return fmt.Errorf("%d: unknown key %q", key.Line, key.Value)
+		}
+	}
+	return nil
+}
+
+// BindFlags 将 analyzer 参数的值设置到 v 中带有 flag tag 的字段上，
+// onlySet 为 true 时，只设置命令行中明确指定了的参数
+func BindFlags(a *analysis.Analyzer, v any, onlySet bool) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a pointer to struct, got %T", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name := rt.Field(i).Tag.Get("flag")
+		if name == "" {
+			continue
+		}
+		f := a.Flags.Lookup(name)
+		if f == nil {
+			return fmt.Errorf("%s: field %s: no flag %q", a.Name, rt.Field(i).Name, name)
+		}
+		if onlySet && !isFlagSet(a, name) {
+			continue
+		}
+		if err := setField(rv.Field(i), f.Value); err != nil {
+			return fmt.Errorf("%s: flag %q: %w", a.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// isFlagSet 判断 analyzer 的参数是否明确指定了，
+// 包括命令行参数(不加前缀或者以 "name." 为前缀)和直接设置的 Analyzer.Flags
+func isFlagSet(a *analysis.Analyzer, name string) bool {
+	var set bool
+	a.Flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	flag.CommandLine.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name || f.Name == a.Name+"."+name
+	})
+	return set
+}
+
+// setField 使用参数的值设置字段，逗号分隔的值可以设置到 []string 字段
+func setField(field reflect.Value, value flag.Value) error {
+	if g, ok := value.(flag.Getter); ok {
+		if gv := reflect.ValueOf(g.Get()); gv.IsValid() && gv.Type().AssignableTo(field.Type()) {
+			field.Set(gv)
+			return nil
+		}
+	}
+	str := value.String()
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String {
+		items := reflect.MakeSlice(field.Type(), 0, 0)
+		for _, item := range strings.Split(str, ",") {
+			if item != "" {
+				items = reflect.Append(items, reflect.ValueOf(item).Convert(field.Type().Elem()))
+			}
+		}
+		field.Set(items)
+		return nil
+	}
+	if field.Kind() == reflect.String {
+		field.SetString(str)
+		return nil
+	}
+	return yaml.Unmarshal([]byte(str), field.Addr().Interface())
+}
+
+// PackageDir 返回 pass 所在 package 的目录
+func PackageDir(pass *analysis.Pass) string {
+	for _, f := range pass.Files {
+		if tf := pass.Fset.File(f.Pos()); tf != nil {
+			return filepath.Dir(tf.Name())
+		}
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
@@ -0,0 +1,164 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// configAnalyzer 用于测试配置文件的 analyzer
+var configAnalyzer = &analysis.Analyzer{
+	Name: "zpass_config_demo",
+	Doc:  "config demo",
+	Run:  func(*analysis.Pass) (any, error) { return nil, nil },
+}
+
+func init() {
+	configAnalyzer.Flags.Int("max-depth", 5, "")
+	Register(configAnalyzer)
+}
+
+type demoConfig struct {
+	SafeFuncs []string `yaml:"safe_funcs"`
+	MaxDepth  int      `yaml:"max_depth" flag:"max-depth"`
+}
+
+func writeConfig(t *testing.T, name string, content string) string {
+	t.Helper()
+	fp := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fp
+}
+
+func TestDecodeConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    demoConfig
+		wantErr string
+	}{
+		{
+			name:    "short name",
+			file:    ".zpass.yaml",
+			content: "config_demo:\n  safe_funcs: [a.Go]\n  max_depth: 3\n",
+			want:    demoConfig{SafeFuncs: []string{"a.Go"}, MaxDepth: 3},
+		},
+		{
+			name:    "full name",
+			file:    ".zpass.yaml",
+			content: "zpass_config_demo:\n  max_depth: 3\n",
+			want:    demoConfig{MaxDepth: 3},
+		},
+		{
+			name:    "json",
+			file:    ".zpass.json",
+			content: `{"config_demo": {"safe_funcs": ["a.Go"]}}`,
+			want:    demoConfig{SafeFuncs: []string{"a.Go"}},
+		},
+		{
+			name: "empty file",
+			file: ".zpass.yaml",
+		},
+		{
+			name:    "unknown key",
+			file:    ".zpass.yaml",
+			content: "config_demo:\n  max_depth: 3\n  safe_func: [a.Go]\n",
+			wantErr: `.zpass.yaml:3: unknown key "safe_func"`,
+		},
+		{
+			name:    "unknown analyzer",
+			file:    ".zpass.yaml",
+			content: "config_demo:\n  max_depth: 3\nconfig_dmeo:\n  max_depth: 4\n",
+			wantErr: `.zpass.yaml:3: unknown analyzer "config_dmeo", did you mean "config_demo"`,
+		},
+		{
+			// 其他程序中的 analyzer 的配置被忽略
+			name:    "other analyzer",
+			file:    ".zpass.yaml",
+			content: "other_linter:\n  max_depth: 4\nconfig_demo:\n  max_depth: 3\n",
+			want:    demoConfig{MaxDepth: 3},
+		},
+		{
+			name:    "not a mapping",
+			file:    ".zpass.yaml",
+			content: "- config_demo\n",
+			wantErr: `.zpass.yaml:1: want a mapping`,
+		},
+		{
+			name:    "invalid value",
+			file:    ".zpass.yaml",
+			content: "config_demo:\n  max_depth: x\n",
+			wantErr: "config_demo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := writeConfig(t, tt.file, tt.content)
+			var got demoConfig
+			err := DecodeConfig(fp, configAnalyzer, &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "go_recover", b: "go_recover", want: 0},
+		{a: "go_recovr", b: "go_recover", want: 1},
+		{a: "go_rceover", b: "go_recover", want: 2},
+		{a: "", b: "abc", want: 3},
+		{a: "other_linter", b: "go_recover", want: 9},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestBindFlags(t *testing.T) {
+	var cfg demoConfig
+	if err := BindFlags(configAnalyzer, &cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxDepth != 5 {
+		t.Fatalf("got max_depth %d, want default 5", cfg.MaxDepth)
+	}
+	// 没有明确指定的参数不覆盖配置文件中的值
+	cfg.MaxDepth = 3
+	if err := BindFlags(configAnalyzer, &cfg, true); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxDepth != 3 {
+		t.Fatalf("got max_depth %d, want 3 from config", cfg.MaxDepth)
+	}
+	if err := BindFlags(configAnalyzer, cfg, false); err == nil {
+		t.Fatal("not a pointer: want error")
+	}
+}
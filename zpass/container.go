@@ -8,79 +8,74 @@ import (
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/token"
 	"go/types"
 	"log"
-	"sync/atomic"
 
 	"github.com/fsgo/fsgo/fssync"
 	"golang.org/x/tools/go/analysis"
 )
 
+// Container analyzer 之间共享的状态
+//
+// 不保存 pass，依赖 package 中的定义通过 NewInitAnalyzer 导出的 PackageIndex fact 查找，
+// 所以也可以在每个 package 单独进程分析的 driver(如 go vet -vettool)中使用
 type Container struct {
-	Tests    bool
-	passList fssync.Map[string, *analysis.Pass]
-	current  atomic.Pointer[analysis.Pass]
-}
+	Tests TestMode
 
-func (c *Container) AddPass(p *analysis.Pass) {
-	if IsTestPkg(p.Pkg.Path()) && !c.Tests {
-		return
-	}
-	pp := PkgPath(p.Pkg.Path())
-	c.passList.Store(pp, p)
-	if IsTrace() {
-		log.Printf("[%s] AddPass %03d, pkg: %s\n", p.Analyzer.Name, c.passList.Count(), pp)
-	}
-}
+	// files 已解析的依赖 package 的文件
+	files fssync.Map[parsedFile, *ast.File]
 
-func (c *Container) SetCurrentPass(p *analysis.Pass) {
-	c.current.Store(p)
+	// initAnalyzer NewInitAnalyzer 创建的 analyzer，用于读取其结果 *Index
+	initAnalyzer *analysis.Analyzer
 }
 
-func (c *Container) CurrentPass() *analysis.Pass {
-	return c.current.Load()
+type parsedFile struct {
+	fset *token.FileSet
+	name string
 }
 
-func (c *Container) FindPass(pkg string) *analysis.Pass {
-	v, _ := c.passList.Load(pkg)
-	return v
+// FindDecl 从 PackageIndex 中查找对象的定义，
+// pass 所属的 analyzer 需要依赖 NewInitAnalyzer 创建的 analyzer
+func (c *Container) FindDecl(pass *analysis.Pass, ov types.Object) (*DeclIndex, bool) {
+	if c.initAnalyzer == nil {
+		return nil, false
+	}
+	idx, _ := pass.ResultOf[c.initAnalyzer].(*Index)
+	return idx.Lookup(ov)
 }
 
-func (c *Container) FindAstFileByObject(ov types.Object) (ap *analysis.Pass, f *ast.File, err error) {
-	curPass := c.CurrentPass()
-	foundPass := c.FindPass(ov.Pkg().Path())
-	p := ov.Pos()
-	tokenFile := curPass.Fset.File(p)
-
+// FindAstFileByObject 查找对象 ov 定义所在的文件，
+// 当前 package 的对象直接在 pass 中查找，
+// 依赖 package 的对象使用 PackageIndex 找到所在文件并解析，解析的文件会被缓存
+func (c *Container) FindAstFileByObject(pass *analysis.Pass, ov types.Object) (*ast.File, error) {
 	if IsTrace() {
-		log.Printf("[FindAstFile] curPkg=%s, ov(Pkg=%s, Name=%s), tokenFile=%s, foundPass=%v\n",
-			curPass.Pkg.Path(),
-			ov.Pkg().Path(),
-			ov.Name(),
-			tokenFile.Name(),
-			foundPass != nil,
-		)
+		log.Printf("[FindAstFile] curPkg=%s, ov(Pkg=%s, Name=%s)\n", pass.Pkg.Path(), ov.Pkg().Path(), ov.Name())
 	}
-
-	if foundPass != nil {
-		for _, astFile := range foundPass.Files {
-			tokenFile2 := foundPass.Fset.File(astFile.Pos())
-			if tokenFile.Name() == tokenFile2.Name() {
-				return foundPass, astFile, nil
+	if ov.Pkg() == pass.Pkg {
+		if tf := pass.Fset.File(ov.Pos()); tf != nil {
+			for _, astFile := range pass.Files {
+				if pass.Fset.File(astFile.Pos()) == tf {
+					return astFile, nil
+				}
 			}
 		}
-		return nil, nil, fmt.Errorf("not found %s in pkg %s", tokenFile.Name(), ov.Pkg().Path())
 	}
-
-	// 目前已解析并加载所有依赖，所以下面的理论不会被执行到
-	mod := parser.Mode(0) | parser.ParseComments
-	f, err = parser.ParseFile(curPass.Fset, tokenFile.Name(), nil, mod)
+	di, ok := c.FindDecl(pass, ov)
+	if !ok {
+		return nil, fmt.Errorf("not found %s in index of pkg %s", DeclKey(ov), ov.Pkg().Path())
+	}
+	key := parsedFile{fset: pass.Fset, name: di.File}
+	if f, ok := c.files.Load(key); ok {
+		return f, nil
+	}
+	f, err := parser.ParseFile(pass.Fset, di.File, nil, parser.ParseComments)
 	if IsDebugVerbose() {
-		log.Println("parser.ParseFile:", tokenFile.Name(), ov.Pkg().Path(), err)
+		log.Println("parser.ParseFile:", di.File, ov.Pkg().Path(), err)
 	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("parseFile %s failed: %v", tokenFile.Name(), err)
+		return nil, fmt.Errorf("parseFile %s failed: %v", di.File, err)
 	}
-	curPass.Files = append(curPass.Files, f)
-	return curPass, f, nil
+	f, _ = c.files.LoadOrStore(key, f)
+	return f, nil
 }
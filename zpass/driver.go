@@ -0,0 +1,206 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+var (
+	outputFormat = FormatText
+	withTests    = true
+)
+
+// StdDriverWanted 判断是否需要使用 x/tools 的 singlechecker/multichecker 运行：
+// 作为 go vet -vettool 运行，或者使用了 -fix、-c 等只有其支持的参数，
+// -json 使用 RegisterFlags 注册的，和 -format=json 相同
+func StdDriverWanted(args []string) bool {
+	for _, arg := range args {
+		if strings.HasSuffix(arg, ".cfg") {
+			return true
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch name {
+		case "V", "flags", "fix", "diff", "c":
+			return true
+		}
+	}
+	return false
+}
+
+// VetxOnly 作为 go vet -vettool 运行时，当前 package 是否只是用于导出 fact 的依赖，
+// 此时诊断信息不会输出，analyzer 不应记录其问题(如写入基线)
+var VetxOnly = sync.OnceValue(func() bool {
+	if len(os.Args) < 2 || !strings.HasSuffix(os.Args[len(os.Args)-1], ".cfg") {
+		return false
+	}
+	content, err := os.ReadFile(os.Args[len(os.Args)-1])
+	if err != nil {
+		return false
+	}
+	var cfg struct {
+		VetxOnly bool
+	}
+	return json.Unmarshal(content, &cfg) == nil && cfg.VetxOnly
+})
+
+// RegisterFlags 注册 -debug、-test、-format 以及 analyzers 的参数，
+// 只有一个 analyzer 时参数不加前缀，和 singlechecker 一致，否则以 "name." 为前缀
+func RegisterFlags(analyzers ...*analysis.Analyzer) {
+	flag.String("debug", "", "debug flags, any subset of \"fpstv\"\nt prints time and allocations of each analyzer and the slowest packages at exit")
+	flag.StringVar(&cpuProfile, "cpuprofile", "", "write cpu profile to file, samples are labeled with analyzer and package")
+	flag.StringVar(&memProfile, "memprofile", "", "write allocation profile to file")
+	RegisterCacheFlag()
+	RegisterFilterFlags()
+	RegisterPathStyleFlag()
+	flag.BoolVar(&withTests, "test", withTests, "indicates whether test files should be analyzed, too\n-test=false doesn't load test files at all, analyzers may have flags for how to report findings in them")
+	flag.BoolFunc("json", "emit JSON output, same as -format=json", func(s string) error {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		if v {
+			outputFormat = FormatJSON
+		} else if outputFormat == FormatJSON {
+			outputFormat = FormatText
+		}
+		return nil
+	})
+	flag.Func("format", "output format: "+strings.Join(formats, "|")+" (default "+outputFormat+")", func(s string) error {
+		if !slices.Contains(formats, s) {
+			return fmt.Errorf("unsupported format %q", s)
+		}
+		outputFormat = s
+		return nil
+	})
+	for _, a := range analyzers {
+		prefix := a.Name + "."
+		if len(analyzers) == 1 {
+			prefix = ""
+		}
+		a.Flags.VisitAll(func(f *flag.Flag) {
+			flag.Var(f.Value, prefix+f.Name, f.Usage)
+		})
+	}
+}
+
+// OutputFormat 返回 -format 指定的诊断信息的输出格式，只有 FormatText 输出到 stderr，其他的都输出到 stdout
+func OutputFormat() string {
+	return outputFormat
+}
+
+// Analyze 加载 patterns 对应的 package，并运行 analyzers
+func Analyze(analyzers []*analysis.Analyzer, patterns []string) (*checker.Graph, error) {
+	if err := analysis.Validate(analyzers); err != nil {
+		return nil, err
+	}
+	cfg := &packages.Config{
+		Mode:  packages.LoadAllSyntax | packages.NeedModule,
+		Tests: withTests,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return nil, fmt.Errorf("%d errors during loading", n)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no packages matched %q", patterns)
+	}
+	opts := &checker.Options{
+		Sequential:  IsDebugParallel(),
+		SanityCheck: IsDebugSanity(),
+	}
+	if IsDebugFacts() {
+		opts.FactLog = os.Stderr
+	}
+	ApplyFilter(analyzers...)
+	stop, err := startProfile(analyzers)
+	if err != nil {
+		return nil, err
+	}
+	analyzing.Store(true)
+	defer analyzing.Store(false)
+	g, err := checker.Analyze(analyzers, pkgs, opts)
+	if err1 := stop(g); err1 != nil && err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = runFinishers(analyzers)
+	}
+	return g, err
+}
+
+// Results 返回 analyzer 在所有 root package 上的结果
+func Results[T any](g *checker.Graph, a *analysis.Analyzer) []T {
+	var rs []T
+	for _, act := range g.Roots {
+		if act.Analyzer != a || act.Err != nil {
+			continue
+		}
+		if r, ok := act.Result.(T); ok {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// PrintDiagnostics 按照 -format 输出诊断信息，返回进程的退出码：
+// 有 analyzer 执行失败时为 1，text 格式有 SeverityError 级别的诊断信息时为 3，和 singlechecker 一致
+//
+// 非 text 格式时，执行失败的 analyzer 除了在输出中，也会输出到 stderr
+func PrintDiagnostics(g *checker.Graph) int {
+	exitCode := 0
+	for _, ae := range actionErrors(g) {
+		exitCode = 1
+		if outputFormat != FormatText {
+			fmt.Fprintf(os.Stderr, "%s: %s: %v\n", ae.Package, ae.Analyzer, ae.Err)
+		}
+	}
+	var err error
+	switch outputFormat {
+	case FormatJSON:
+		err = g.PrintJSON(os.Stdout)
+	case FormatSARIF:
+		err = WriteSARIF(os.Stdout, g)
+	case FormatCheckstyle:
+		err = WriteCheckstyle(os.Stdout, g)
+	default:
+		err = WriteText(os.Stderr, g)
+		if exitCode == 0 && hasError(Diagnostics(g)) {
+			exitCode = 3
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return exitCode
+}
+
+// hasError 是否有 SeverityError 级别的诊断信息
+func hasError(ds []Diagnostic) bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
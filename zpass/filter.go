@@ -0,0 +1,300 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// FileFilter 过滤 analyzer 检查的文件和 package，所有 analyzer 共享，见 DefaultFilter
+//
+// 默认跳过非 .go 文件、生成的代码(带有 "// Code generated ... DO NOT EDIT." 注释)和 vendor 目录下的文件
+type FileFilter struct {
+	// Generated 是否检查生成的代码
+	Generated bool
+
+	// Vendor 是否检查 vendor 目录下的文件
+	Vendor bool
+
+	// Exclude 不检查的文件，glob 格式，见 MatchPath
+	Exclude []string
+
+	// Include 不为空时，只检查匹配的文件，glob 格式，见 MatchPath
+	Include []string
+
+	// ExcludePackages 不检查的 package，匹配 package path 的正则
+	ExcludePackages []*regexp.Regexp
+
+	// IncludePackages 不为空时，只检查匹配的 package
+	IncludePackages []*regexp.Regexp
+}
+
+// DefaultFilter 所有 analyzer 共享的 FileFilter，由 -generated、-exclude 等参数设置
+var DefaultFilter = &FileFilter{}
+
+// RegisterFilterFlags 注册 -generated、-vendor、-exclude、-include、-exclude-pkg、-include-pkg 参数，
+// RegisterFlags 会调用，使用 singlechecker 等 driver 的程序在其解析参数前调用
+func RegisterFilterFlags() {
+	if flag.Lookup("exclude") != nil {
+		return
+	}
+	f := DefaultFilter
+	flag.BoolVar(&f.Generated, "generated", f.Generated, `check generated files, which have a "// Code generated ... DO NOT EDIT." comment`)
+	flag.BoolVar(&f.Vendor, "vendor", f.Vendor, "check files in vendor dirs")
+	flag.Var((*globList)(&f.Exclude), "exclude", "comma-separated list of file globs not to check, e.g. '*.pb.go,internal/mock/**'\nglobs without / match file and dir names")
+	flag.Var((*globList)(&f.Include), "include", "comma-separated list of file globs, only matched files are checked")
+	flag.Var((*regexpList)(&f.ExcludePackages), "exclude-pkg", "regexp of package paths not to check, can be repeated")
+	flag.Var((*regexpList)(&f.IncludePackages), "include-pkg", "regexp of package paths, only matched packages are checked, can be repeated")
+}
+
+// SkipPackage 是否不检查 package pkgPath
+func (f *FileFilter) SkipPackage(pkgPath string) bool {
+	pkgPath = PkgPath(pkgPath)
+	if len(f.IncludePackages) > 0 && !matchAny(f.IncludePackages, pkgPath) {
+		return true
+	}
+	return matchAny(f.ExcludePackages, pkgPath)
+}
+
+func matchAny(regs []*regexp.Regexp, s string) bool {
+	for _, reg := range regs {
+		if reg.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipFile 是否不检查文件 file，reason 为不检查的原因
+func (f *FileFilter) SkipFile(fset *token.FileSet, file *ast.File) (skip bool, reason string) {
+	tf := fset.File(file.Pos())
+	if tf == nil {
+		return false, ""
+	}
+	name := tf.Name()
+	if !strings.HasSuffix(name, ".go") {
+		return true, "not a go file"
+	}
+	if !f.Generated && ast.IsGenerated(file) {
+		return true, "generated"
+	}
+	return f.skipPath(name)
+}
+
+func (f *FileFilter) skipPath(name string) (skip bool, reason string) {
+	name = filterName(name)
+	if !f.Vendor && MatchPath("vendor", name) {
+		return true, "in vendor dir"
+	}
+	for _, pattern := range f.Exclude {
+		if MatchPath(pattern, name) {
+			return true, "excluded by " + pattern
+		}
+	}
+	if len(f.Include) == 0 {
+		return false, ""
+	}
+	for _, pattern := range f.Include {
+		if MatchPath(pattern, name) {
+			return false, ""
+		}
+	}
+	return true, "not included"
+}
+
+// key 用于缓存的 key，过滤条件变化时缓存失效
+func (f *FileFilter) key() string {
+	return fmt.Sprintf("generated=%t vendor=%t exclude=%q include=%q exclude-pkg=%q include-pkg=%q",
+		f.Generated, f.Vendor, f.Exclude, f.Include, f.ExcludePackages, f.IncludePackages)
+}
+
+var filterWd = sync.OnceValue(func() string {
+	wd, _ := os.Getwd()
+	return wd
+})
+
+// filterName 返回匹配 glob 时使用的路径：在当前目录下时为相对路径，否则为绝对路径，
+// 这样当前目录之上的目录名(如 /home/work/vendor/project)不会影响匹配结果
+func filterName(name string) string {
+	if rel, err := filepath.Rel(filterWd(), name); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(name)
+}
+
+// MatchPath 判断 / 分隔的路径 name 是否匹配 glob pattern：
+//   - pattern 中没有 / 时，匹配文件名或者任意一级目录名，如 "*.pb.go"、"testdata"
+//   - 否则从头开始匹配，"**" 匹配任意多级目录，如 "internal/**/mock_*.go"
+//   - 匹配了目录时，目录下的所有文件都匹配，如 "internal/mock"
+//
+// 每一级的匹配规则和 path.Match 一致
+func MatchPath(pattern string, name string) bool {
+	pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+	segs := strings.Split(strings.Trim(name, "/"), "/")
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		for _, seg := range segs {
+			if ok, _ := path.Match(pattern, seg); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return matchSegs(strings.Split(pattern, "/"), segs)
+}
+
+// matchSegs 按照每一级目录匹配，pattern 匹配完时 name 有剩余也认为匹配，即匹配了目录
+func matchSegs(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegs(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchSegs(pattern[1:], name[1:])
+}
+
+type filteredFile struct {
+	files   []*ast.File
+	skipped map[*token.File]bool
+}
+
+// filter 返回 pass 中需要检查的文件，每次运行时计算，不缓存，分析完 package 后即可释放
+func (f *FileFilter) filter(pass *analysis.Pass) *filteredFile {
+	ff := &filteredFile{skipped: make(map[*token.File]bool)}
+	skipPkg := f.SkipPackage(pass.Pkg.Path())
+	for _, file := range pass.Files {
+		skip, reason := skipPkg, "package excluded"
+		if !skip {
+			skip, reason = f.SkipFile(pass.Fset, file)
+		}
+		if !skip {
+			ff.files = append(ff.files, file)
+			continue
+		}
+		tf := pass.Fset.File(file.Pos())
+		if tf == nil {
+			continue
+		}
+		ff.skipped[tf] = true
+		if IsDebugVerbose() {
+			log.Printf("[%s] skip file %s: %s\n", pass.Analyzer.Name, tf.Name(), reason)
+		}
+	}
+	return ff
+}
+
+var filterWrapped sync.Map // *analysis.Analyzer -> bool
+
+// ApplyFilter 让 analyzers 使用 DefaultFilter，每个 analyzer 只会处理一次：
+//   - 没有 fact 的 analyzer，pass.Files 中只有需要检查的文件
+//   - 有 fact 的 analyzer 需要所有文件导出 fact，pass.Files 不变
+//   - 依赖 inspect 的 analyzer，pass.ResultOf 中的 inspector 只遍历需要检查的文件
+//   - 在跳过的文件中的诊断信息都不报告
+//
+// 只修改 analyzers 自身，不处理其依赖的 analyzer(如 inspect)，它们可能被其他 linter 共享，
+// 如在 golangci-lint 中运行时。
+//
+// Analyze 会调用，使用 singlechecker 等 driver 的程序在其运行前调用
+func ApplyFilter(analyzers ...*analysis.Analyzer) {
+	for _, a := range analyzers {
+		if _, loaded := filterWrapped.LoadOrStore(a, true); loaded {
+			continue
+		}
+		run := a.Run
+		a.Run = func(pass *analysis.Pass) (any, error) {
+			ff := DefaultFilter.filter(pass)
+			if len(ff.skipped) == 0 {
+				return run(pass)
+			}
+			// pass 和 pass.ResultOf 都只属于 a 的本次运行，修改后恢复
+			files, report := pass.Files, pass.Report
+			insp, hasInspect := pass.ResultOf[inspect.Analyzer]
+			defer func() {
+				pass.Files, pass.Report = files, report
+				if hasInspect {
+					pass.ResultOf[inspect.Analyzer] = insp
+				}
+			}()
+			if len(a.FactTypes) == 0 {
+				pass.Files = ff.files
+			}
+			if hasInspect {
+				pass.ResultOf[inspect.Analyzer] = inspector.New(ff.files)
+			}
+			pass.Report = func(d analysis.Diagnostic) {
+				if !ff.skipped[pass.Fset.File(d.Pos)] {
+					report(d)
+				}
+			}
+			return run(pass)
+		}
+	}
+}
+
+// globList 逗号分隔的 glob 列表，可以多次指定
+type globList []string
+
+func (g *globList) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *globList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if _, err := path.Match(v, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", v, err)
+		}
+		*g = append(*g, v)
+	}
+	return nil
+}
+
+// regexpList 正则列表，可以多次指定
+type regexpList []*regexp.Regexp
+
+func (r *regexpList) String() string {
+	items := make([]string, 0, len(*r))
+	for _, reg := range *r {
+		items = append(items, reg.String())
+	}
+	return strings.Join(items, ",")
+}
+
+func (r *regexpList) Set(value string) error {
+	reg, err := regexp.Compile(value)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, reg)
+	return nil
+}
@@ -0,0 +1,182 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.pb.go", name: "api/user.pb.go", want: true},
+		{pattern: "*.pb.go", name: "api/user.go", want: false},
+		{pattern: "testdata", name: "a/testdata/b.go", want: true},
+		{pattern: "testdata", name: "a/testdata2/b.go", want: false},
+		{pattern: "vendor", name: "/home/work/vendor/a.go", want: true},
+		{pattern: "internal/mock", name: "internal/mock/a.go", want: true},
+		{pattern: "internal/mock", name: "a/internal/mock/a.go", want: false},
+		{pattern: "internal/mock/", name: "internal/mock/a.go", want: true},
+		{pattern: "internal/**/mock_*.go", name: "internal/mock_a.go", want: true},
+		{pattern: "internal/**/mock_*.go", name: "internal/a/b/mock_a.go", want: true},
+		{pattern: "internal/**/mock_*.go", name: "internal/a/b/a.go", want: false},
+		{pattern: "**/mock", name: "a/b/mock/a.go", want: true},
+		{pattern: "**", name: "a.go", want: true},
+		{pattern: "a/*.go", name: "a/b/c.go", want: false},
+		{pattern: "[", name: "a/[", want: false},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFileFilter(t *testing.T) {
+	tests := []struct {
+		filter FileFilter
+		name   string
+		want   bool
+	}{
+		{name: "a/b.go", want: false},
+		{name: "vendor/a/b.go", want: true},
+		{filter: FileFilter{Vendor: true}, name: "vendor/a/b.go", want: false},
+		{filter: FileFilter{Exclude: []string{"*.pb.go"}}, name: "a/b.pb.go", want: true},
+		{filter: FileFilter{Include: []string{"a"}}, name: "a/b.go", want: false},
+		{filter: FileFilter{Include: []string{"a"}}, name: "b/b.go", want: true},
+		// Exclude 优先
+		{filter: FileFilter{Include: []string{"a"}, Exclude: []string{"b.go"}}, name: "a/b.go", want: true},
+	}
+	for _, tt := range tests {
+		if got, reason := tt.filter.skipPath(tt.name); got != tt.want {
+			t.Errorf("%+v skipPath(%q) = %v (%s), want %v", tt.filter, tt.name, got, reason, tt.want)
+		}
+	}
+
+	pkgs := FileFilter{
+		ExcludePackages: []*regexp.Regexp{regexp.MustCompile(`/internal/mock$`)},
+		IncludePackages: []*regexp.Regexp{regexp.MustCompile(`^example\.com/`)},
+	}
+	for pkgPath, want := range map[string]bool{
+		"example.com/a":               false,
+		"example.com/a/internal/mock": true,
+		"github.com/a":                true,
+	} {
+		if got := pkgs.SkipPackage(pkgPath); got != want {
+			t.Errorf("SkipPackage(%q) = %v, want %v", pkgPath, got, want)
+		}
+	}
+}
+
+func TestGlobList(t *testing.T) {
+	var g globList
+	for _, v := range []string{"*.pb.go, internal/mock ,", "testdata"} {
+		if err := g.Set(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := globList{"*.pb.go", "internal/mock", "testdata"}
+	if !reflect.DeepEqual(g, want) {
+		t.Fatalf("got %q, want %q", g, want)
+	}
+	if err := g.Set("a/["); err == nil {
+		t.Fatal("invalid glob: want error")
+	}
+}
+
+type filterFact struct{}
+
+func (*filterFact) AFact() {}
+
+// countFiles 返回 inspector 遍历到的文件数
+func countFiles(pass *analysis.Pass) int {
+	var n int
+	pass.ResultOf[inspect.Analyzer].(*inspector.Inspector).Preorder([]ast.Node{(*ast.File)(nil)}, func(ast.Node) {
+		n++
+	})
+	return n
+}
+
+func newFilterAnalyzer(name string, facts []analysis.Fact) *analysis.Analyzer {
+	// required 依赖 inspect，且不使用 ApplyFilter，能看到所有文件
+	required := &analysis.Analyzer{
+		Name:       name + "_required",
+		Doc:        "count files",
+		Requires:   []*analysis.Analyzer{inspect.Analyzer},
+		Run:        func(pass *analysis.Pass) (any, error) { return countFiles(pass), nil },
+		ResultType: reflect.TypeOf(0),
+	}
+	return &analysis.Analyzer{
+		Name:      name,
+		Doc:       "report files and funcs",
+		Requires:  []*analysis.Analyzer{inspect.Analyzer, required},
+		FactTypes: facts,
+		Run: func(pass *analysis.Pass) (any, error) {
+			var first *ast.File
+			pass.ResultOf[inspect.Analyzer].(*inspector.Inspector).Preorder([]ast.Node{(*ast.File)(nil)}, func(node ast.Node) {
+				if first == nil {
+					first = node.(*ast.File)
+				}
+			})
+			pass.Reportf(first.Name.Pos(), "%d files, %d pass files, required %d files",
+				countFiles(pass), len(pass.Files), pass.ResultOf[required])
+			for _, f := range pass.Files {
+				for _, decl := range f.Decls {
+					if fd, ok := decl.(*ast.FuncDecl); ok {
+						pass.Reportf(fd.Pos(), "func %s", fd.Name.Name)
+					}
+				}
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestApplyFilter(t *testing.T) {
+	exclude := DefaultFilter.Exclude
+	DefaultFilter.Exclude = []string{"excluded.go"}
+	t.Cleanup(func() {
+		DefaultFilter.Exclude = exclude
+	})
+
+	funcPtr := func(a *analysis.Analyzer) uintptr {
+		return reflect.ValueOf(a.Run).Pointer()
+	}
+	inspectRun := funcPtr(inspect.Analyzer)
+
+	tests := []struct {
+		pkg   string
+		facts []analysis.Fact
+	}{
+		{pkg: "filter"},
+		// 有 fact 时 pass.Files 中有所有文件，跳过的文件中的诊断信息不报告
+		{pkg: "filterfact", facts: []analysis.Fact{new(filterFact)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			a := newFilterAnalyzer(fmt.Sprintf("zpass_filter_%s", tt.pkg), tt.facts)
+			required := a.Requires[1]
+			requiredRun := funcPtr(required)
+			ApplyFilter(a)
+			// 依赖的 analyzer 不修改
+			if funcPtr(inspect.Analyzer) != inspectRun || funcPtr(required) != requiredRun {
+				t.Fatal("required analyzers changed by ApplyFilter")
+			}
+			analysistest.Run(t, analysistest.TestData(), a, tt.pkg)
+		})
+	}
+}
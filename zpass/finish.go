@@ -0,0 +1,50 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var (
+	finishMu  sync.Mutex
+	finishers = make(map[*analysis.Analyzer][]func() error)
+
+	// analyzing 是否在 Analyze 中运行
+	analyzing atomic.Bool
+)
+
+// OnFinish 注册 Analyze 分析完所有 package 后执行的函数 fn，如写入汇总所有 package 的文件，
+// 只有 a 在本次运行的 analyzer 中时才执行
+//
+// 使用 singlechecker、go vet 等 driver 时不会执行，此时 Analyzing 返回 false，
+// analyzer 需要在每个 package 分析完后自行处理
+func OnFinish(a *analysis.Analyzer, fn func() error) {
+	finishMu.Lock()
+	defer finishMu.Unlock()
+	finishers[a] = append(finishers[a], fn)
+}
+
+// Analyzing 是否在 Analyze 中运行，为 true 时 OnFinish 注册的函数会在最后执行
+func Analyzing() bool {
+	return analyzing.Load()
+}
+
+// runFinishers 执行 analyzers 通过 OnFinish 注册的函数
+func runFinishers(analyzers []*analysis.Analyzer) error {
+	finishMu.Lock()
+	defer finishMu.Unlock()
+	var errs []error
+	for _, a := range analyzers {
+		for _, fn := range finishers[a] {
+			errs = append(errs, fn())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -22,7 +22,8 @@ var parserOnce sync.Once
 
 var vv = flag.Bool("vv", false, "show verbose trace logs")
 
-func tryParserFlags() {
+// TryParseFlags 解析命令行参数，读取 debug 等调试选项，只会执行一次
+func TryParseFlags() {
 	parserOnce.Do(func() {
 		flag.Parse()
 		if ft := flag.Lookup("debug"); ft != nil {
@@ -31,6 +32,17 @@ func tryParserFlags() {
 	})
 }
 
+// DisableParseFlags 不再解析命令行参数，TryParseFlags 不会执行 flag.Parse，
+// 用于 golangci-lint 插件等 analyzer 不是由自己的 main 函数运行的场景，参数由其配置设置
+func DisableParseFlags() {
+	parserOnce.Do(func() {})
+}
+
+// SetDebug 设置 debug 选项，和 -debug 参数一致
+func SetDebug(s string) {
+	debug.Store(s)
+}
+
 // IsDebug 判断是否指定 debug 类型
 // Debug is a set of single-letter flags:
 //
@@ -0,0 +1,368 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+// 支持的输出格式
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatSARIF      = "sarif"
+	FormatCheckstyle = "checkstyle"
+)
+
+var formats = []string{FormatText, FormatJSON, FormatSARIF, FormatCheckstyle}
+
+// Diagnostic 诊断信息，位置已转换为 token.Position
+type Diagnostic struct {
+	Analyzer string
+	Doc      string // analyzer 的说明
+	Category string
+	Rule     string   // 规则 ID，没有时为空，见 Rule
+	Severity Severity // 级别，见 SeverityOf
+	URL      string   // 文档地址
+	Pos      token.Position
+	End      token.Position
+	Message  string
+	Related  []RelatedDiagnostic
+}
+
+// RelatedDiagnostic 诊断信息的相关信息，如代码片段
+type RelatedDiagnostic struct {
+	Pos     token.Position
+	End     token.Position
+	Message string
+}
+
+// ruleID 返回 sarif 中的 rule id，没有规则时使用 analyzer 的名字
+func (d Diagnostic) ruleID() string {
+	if d.Rule != "" {
+		return d.Rule
+	}
+	return d.Analyzer
+}
+
+// level 返回 sarif 中的级别
+func (d Diagnostic) level() string {
+	switch d.Severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// Diagnostics 返回所有 root package 上的诊断信息，按照位置排序
+// 同一个文件可能属于多个 package(如 foo 和 foo.test)，重复的只保留一个
+func Diagnostics(g *checker.Graph) []Diagnostic {
+	type key struct {
+		pos      token.Position
+		analyzer string
+		message  string
+	}
+	seen := make(map[key]bool)
+	var ds []Diagnostic
+	for _, act := range g.Roots {
+		for _, d := range act.Diagnostics {
+			pos := act.Package.Fset.Position(d.Pos)
+			k := key{pos: pos, analyzer: act.Analyzer.Name, message: d.Message}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			item := Diagnostic{
+				Analyzer: act.Analyzer.Name,
+				Doc:      act.Analyzer.Doc,
+				Category: d.Category,
+				Severity: SeverityOf(d.Category),
+				URL:      d.URL,
+				Pos:      pos,
+				Message:  d.Message,
+			}
+			if rule := LookupRule(d.Category); rule != nil {
+				item.Rule = rule.ID
+			}
+			if d.End.IsValid() {
+				item.End = act.Package.Fset.Position(d.End)
+			}
+			for _, r := range d.Related {
+				rd := RelatedDiagnostic{Pos: act.Package.Fset.Position(r.Pos), Message: r.Message}
+				if r.End.IsValid() {
+					rd.End = act.Package.Fset.Position(r.End)
+				}
+				item.Related = append(item.Related, rd)
+			}
+			ds = append(ds, item)
+		}
+	}
+	sort.SliceStable(ds, func(i, j int) bool {
+		a, b := ds[i].Pos, ds[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return ds
+}
+
+// uri 返回按照 -path-style 格式的 / 分隔的路径，用于 sarif 等格式
+func uri(name string) string {
+	return filepath.ToSlash(RelPath(name))
+}
+
+// actionError 执行失败的 analyzer
+type actionError struct {
+	Analyzer string
+	Package  string
+	Err      error
+}
+
+// actionErrors 返回 root package 上执行失败的 analyzer
+func actionErrors(g *checker.Graph) []actionError {
+	var errs []actionError
+	for _, act := range g.Roots {
+		if act.Err != nil {
+			errs = append(errs, actionError{Analyzer: act.Analyzer.Name, Package: act.Package.PkgPath, Err: act.Err})
+		}
+	}
+	return errs
+}
+
+// WriteText 以文本格式输出诊断信息和执行失败的 analyzer，
+// 相关信息(如代码片段)缩进输出在诊断信息之后
+func WriteText(w io.Writer, g *checker.Graph) error {
+	bw := bufio.NewWriter(w)
+	for _, ae := range actionErrors(g) {
+		fmt.Fprintf(bw, "%s: %v\n", ae.Analyzer, ae.Err)
+	}
+	for _, d := range Diagnostics(g) {
+		fmt.Fprintf(bw, "%s: %s\n", FormatPosition(d.Pos), d.Message)
+		for _, r := range d.Related {
+			fmt.Fprintf(bw, "\t%s: %s\n", FormatPosition(r.Pos), strings.ReplaceAll(r.Message, "\n", "\n\t"))
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteSARIF 以 SARIF 2.1.0 格式输出诊断信息，规则的 ID 作为 rule id，没有规则时使用 analyzer 的名字，
+// 执行失败的 analyzer 作为 invocation 的 toolExecutionNotifications 输出
+func WriteSARIF(w io.Writer, g *checker.Graph) error {
+	type (
+		text struct {
+			Text string `json:"text"`
+		}
+		configuration struct {
+			Level string `json:"level"`
+		}
+		properties struct {
+			Tags []string `json:"tags,omitempty"`
+		}
+		rule struct {
+			ID                   string         `json:"id"`
+			ShortDescription     text           `json:"shortDescription"`
+			HelpURI              string         `json:"helpUri,omitempty"`
+			DefaultConfiguration *configuration `json:"defaultConfiguration,omitempty"`
+			Properties           *properties    `json:"properties,omitempty"`
+		}
+		region struct {
+			StartLine   int `json:"startLine"`
+			StartColumn int `json:"startColumn,omitempty"`
+			EndLine     int `json:"endLine,omitempty"`
+			EndColumn   int `json:"endColumn,omitempty"`
+		}
+		artifact struct {
+			URI string `json:"uri"`
+		}
+		physicalLocation struct {
+			ArtifactLocation artifact `json:"artifactLocation"`
+			Region           region   `json:"region"`
+		}
+		location struct {
+			ID               *int             `json:"id,omitempty"`
+			PhysicalLocation physicalLocation `json:"physicalLocation"`
+			Message          *text            `json:"message,omitempty"`
+		}
+		result struct {
+			RuleID           string     `json:"ruleId"`
+			Level            string     `json:"level"`
+			Message          text       `json:"message"`
+			Locations        []location `json:"locations"`
+			RelatedLocations []location `json:"relatedLocations,omitempty"`
+		}
+		driver struct {
+			Name  string `json:"name"`
+			Rules []rule `json:"rules"`
+		}
+		tool struct {
+			Driver driver `json:"driver"`
+		}
+		notification struct {
+			Level   string `json:"level"`
+			Message text   `json:"message"`
+		}
+		invocation struct {
+			ExecutionSuccessful        bool           `json:"executionSuccessful"`
+			ToolExecutionNotifications []notification `json:"toolExecutionNotifications,omitempty"`
+		}
+		run struct {
+			Tool        tool         `json:"tool"`
+			Invocations []invocation `json:"invocations"`
+			Results     []result     `json:"results"`
+		}
+		log struct {
+			Version string `json:"version"`
+			Schema  string `json:"$schema"`
+			Runs    []run  `json:"runs"`
+		}
+	)
+	newLocation := func(pos, end token.Position) location {
+		rg := region{
+			StartLine:   pos.Line,
+			StartColumn: pos.Column,
+			EndLine:     end.Line,
+			EndColumn:   end.Column,
+		}
+		return location{PhysicalLocation: physicalLocation{ArtifactLocation: artifact{URI: uri(pos.Filename)}, Region: rg}}
+	}
+	r := run{
+		Tool: tool{
+			Driver: driver{
+				Name:  filepath.Base(os.Args[0]),
+				Rules: []rule{},
+			},
+		},
+		Results: []result{},
+	}
+	inv := invocation{ExecutionSuccessful: true}
+	for _, ae := range actionErrors(g) {
+		inv.ExecutionSuccessful = false
+		inv.ToolExecutionNotifications = append(inv.ToolExecutionNotifications, notification{
+			Level:   "error",
+			Message: text{Text: fmt.Sprintf("%s: %s: %v", ae.Package, ae.Analyzer, ae.Err)},
+		})
+	}
+	r.Invocations = []invocation{inv}
+	rules := make(map[string]bool)
+	for _, d := range Diagnostics(g) {
+		id := d.ruleID()
+		if !rules[id] {
+			rules[id] = true
+			item := rule{ID: id}
+			if rl := LookupRule(id); rl != nil {
+				item.ShortDescription = text{Text: rl.Doc}
+				item.HelpURI = rl.URL
+				item.DefaultConfiguration = &configuration{Level: Diagnostic{Severity: rl.severity()}.level()}
+				if rl.Category != "" {
+					item.Properties = &properties{Tags: []string{rl.Category}}
+				}
+			} else {
+				doc, _, _ := strings.Cut(strings.TrimSpace(d.Doc), "\n")
+				item.ShortDescription = text{Text: doc}
+			}
+			r.Tool.Driver.Rules = append(r.Tool.Driver.Rules, item)
+		}
+		res := result{
+			RuleID:    id,
+			Level:     d.level(),
+			Message:   text{Text: d.Message},
+			Locations: []location{newLocation(d.Pos, d.End)},
+		}
+		for i, rd := range d.Related {
+			loc := newLocation(rd.Pos, rd.End)
+			loc.ID = &i
+			loc.Message = &text{Text: rd.Message}
+			res.RelatedLocations = append(res.RelatedLocations, loc)
+		}
+		r.Results = append(r.Results, res)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []run{r},
+	})
+}
+
+// WriteCheckstyle 以 checkstyle 的 xml 格式输出诊断信息，
+// 执行失败的 analyzer 作为 package path 对应的 file 中的 error 输出
+func WriteCheckstyle(w io.Writer, g *checker.Graph) error {
+	type (
+		item struct {
+			Line     int    `xml:"line,attr"`
+			Column   int    `xml:"column,attr"`
+			Severity string `xml:"severity,attr"`
+			Message  string `xml:"message,attr"`
+			Source   string `xml:"source,attr"`
+		}
+		file struct {
+			Name   string `xml:"name,attr"`
+			Errors []item `xml:"error"`
+		}
+		checkstyle struct {
+			XMLName xml.Name `xml:"checkstyle"`
+			Version string   `xml:"version,attr"`
+			Files   []*file  `xml:"file"`
+		}
+	)
+	cs := checkstyle{Version: "5.0"}
+	files := make(map[string]*file)
+	fileOf := func(name string) *file {
+		f, ok := files[name]
+		if !ok {
+			f = &file{Name: name}
+			files[name] = f
+			cs.Files = append(cs.Files, f)
+		}
+		return f
+	}
+	for _, ae := range actionErrors(g) {
+		f := fileOf(ae.Package)
+		f.Errors = append(f.Errors, item{
+			Severity: string(SeverityError),
+			Message:  ae.Err.Error(),
+			Source:   ae.Analyzer,
+		})
+	}
+	for _, d := range Diagnostics(g) {
+		f := fileOf(uri(d.Pos.Filename))
+		f.Errors = append(f.Errors, item{
+			Line:     d.Pos.Line,
+			Column:   d.Pos.Column,
+			Severity: string(d.Severity),
+			Message:  d.Message,
+			Source:   d.ruleID(),
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(cs); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,106 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// loadDemoPackage 加载临时目录中的 package example.com/demo
+func loadDemoPackage(t *testing.T) []*packages.Package {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/demo\n\ngo 1.22\n",
+		"a.go":   "package demo\n\nvar x = 1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &packages.Config{Mode: packages.LoadAllSyntax, Dir: dir, Env: append(os.Environ(), "GOWORK=off", "GOFLAGS=")}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkgs
+}
+
+// failedGraph 返回 analyzer 执行失败的 checker.Graph
+func failedGraph(t *testing.T) *checker.Graph {
+	t.Helper()
+	a := &analysis.Analyzer{
+		Name: "zpass_format_demo",
+		Doc:  "always fails",
+		Run: func(*analysis.Pass) (any, error) {
+			return nil, errors.New("demo failure")
+		},
+	}
+	g, err := checker.Analyze([]*analysis.Analyzer{a}, loadDemoPackage(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestWriteActionErrors(t *testing.T) {
+	g := failedGraph(t)
+
+	bf := &strings.Builder{}
+	if err := WriteSARIF(bf, g); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Invocations []struct {
+				ExecutionSuccessful        bool
+				ToolExecutionNotifications []struct {
+					Level   string
+					Message struct{ Text string }
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(bf.String()), &log); err != nil {
+		t.Fatal(err)
+	}
+	inv := log.Runs[0].Invocations[0]
+	if inv.ExecutionSuccessful || len(inv.ToolExecutionNotifications) != 1 {
+		t.Fatalf("sarif: got invocation %+v, want one failure", inv)
+	}
+	want := "example.com/demo: zpass_format_demo: demo failure"
+	if n := inv.ToolExecutionNotifications[0]; n.Level != "error" || n.Message.Text != want {
+		t.Fatalf("sarif: got notification %+v, want %q", n, want)
+	}
+
+	bf.Reset()
+	if err := WriteCheckstyle(bf, g); err != nil {
+		t.Fatal(err)
+	}
+	want = `<file name="example.com/demo">
+    <error line="0" column="0" severity="error" message="demo failure" source="zpass_format_demo"></error>
+  </file>`
+	if !strings.Contains(bf.String(), want) {
+		t.Fatalf("checkstyle: got:\n%s\nwant:\n%s", bf.String(), want)
+	}
+
+	bf.Reset()
+	if err := WriteText(bf, g); err != nil {
+		t.Fatal(err)
+	}
+	if got := bf.String(); got != "zpass_format_demo: demo failure\n" {
+		t.Fatalf("text: got %q", got)
+	}
+}
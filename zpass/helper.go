@@ -10,6 +10,11 @@ func IsTestPkg(pkg string) bool {
 	return strings.HasSuffix(pkg, ".test") || strings.HasSuffix(pkg, "_test")
 }
 
+// IsTestMainPkg 是否是 go test 生成的 main package，如 foo.test
+func IsTestMainPkg(pkg string) bool {
+	return strings.HasSuffix(pkg, ".test")
+}
+
 func PkgPath(name string) string {
 	after, _ := strings.CutPrefix(name, "vendor/")
 	return after
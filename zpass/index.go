@@ -0,0 +1,148 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// PackageIndex 由 NewInitAnalyzer 创建的 analyzer 导出在 package 上的 fact，记录 package 中顶层的定义，
+// Container 使用它查找依赖 package 中的定义，而不是保存所有的 pass，
+// 这样不需要在整个运行期间保留所有 package 的 AST 和类型信息，
+// 也可以在每个 package 单独进程分析的 driver(如 go vet -vettool)中使用
+type PackageIndex struct {
+	// Decls 定义的名字 -> 定义，名字见 DeclKey
+	Decls map[string]*DeclIndex
+}
+
+func (*PackageIndex) AFact() {}
+
+func (p *PackageIndex) String() string {
+	return "index"
+}
+
+// DeclIndex 一个顶层定义的位置，函数还包含其函数体的摘要
+type DeclIndex struct {
+	File   string // 所在文件
+	Offset int    // 在文件中的开始位置
+	End    int    // 在文件中的结束位置
+
+	// Calls 函数体中静态调用的函数，值为 types.Func.FullName
+	Calls []string `json:",omitempty"`
+
+	// Recover 函数体中是否直接调用了 recover()
+	Recover bool `json:",omitempty"`
+}
+
+// DeclKey 返回对象在 PackageIndex 中的名字，方法为 "类型名.方法名"，其他为对象名
+func DeclKey(obj types.Object) string {
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return obj.Name()
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return fn.Name()
+	}
+	rt := recv.Type()
+	if pt, ok := rt.(*types.Pointer); ok {
+		rt = pt.Elem()
+	}
+	if nt, ok := rt.(*types.Named); ok {
+		return nt.Obj().Name() + "." + fn.Name()
+	}
+	return fn.Name()
+}
+
+// exportIndex 导出当前 package 的 PackageIndex
+func exportIndex(pass *analysis.Pass) {
+	idx := &PackageIndex{Decls: make(map[string]*DeclIndex)}
+	add := func(id *ast.Ident, node ast.Node) {
+		obj := pass.TypesInfo.Defs[id]
+		if obj == nil || id.Name == "_" {
+			return
+		}
+		idx.Decls[DeclKey(obj)] = newDeclIndex(pass.Fset, node)
+	}
+	for _, f := range pass.Files {
+		for _, d := range f.Decls {
+			switch vt := d.(type) {
+			case *ast.FuncDecl:
+				add(vt.Name, vt)
+				if di, ok := idx.Decls[DeclKey(pass.TypesInfo.Defs[vt.Name])]; ok && vt.Body != nil {
+					summarizeBody(pass, vt.Body, di)
+				}
+			case *ast.GenDecl:
+				for _, spec := range vt.Specs {
+					switch st := spec.(type) {
+					case *ast.TypeSpec:
+						add(st.Name, st)
+					case *ast.ValueSpec:
+						for _, name := range st.Names {
+							add(name, st)
+						}
+					}
+				}
+			}
+		}
+	}
+	pass.ExportPackageFact(idx)
+}
+
+func newDeclIndex(fset *token.FileSet, node ast.Node) *DeclIndex {
+	start := fset.Position(node.Pos())
+	return &DeclIndex{
+		File:   start.Filename,
+		Offset: start.Offset,
+		End:    fset.Position(node.End()).Offset,
+	}
+}
+
+// summarizeBody 记录函数体中调用的函数，以及是否调用了 recover()
+func summarizeBody(pass *analysis.Pass, body *ast.BlockStmt, di *DeclIndex) {
+	seen := make(map[string]bool)
+	ast.Inspect(body, func(node ast.Node) bool {
+		ce, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch fn := typeutil.Callee(pass.TypesInfo, ce).(type) {
+		case *types.Builtin:
+			if fn.Name() == "recover" {
+				di.Recover = true
+			}
+		case *types.Func:
+			if name := fn.FullName(); !seen[name] {
+				seen[name] = true
+				di.Calls = append(di.Calls, name)
+			}
+		}
+		return true
+	})
+}
+
+// Index NewInitAnalyzer 的结果，用于读取当前 package 和依赖 package 的 PackageIndex
+// fact 只能由导出它的 analyzer 读取，所以需要通过 init analyzer 的 pass 读取
+type Index struct {
+	pass *analysis.Pass
+}
+
+// Lookup 查找对象的定义，obj 所属的 package 需要是当前 package 或者其依赖
+func (idx *Index) Lookup(obj types.Object) (*DeclIndex, bool) {
+	if idx == nil || obj.Pkg() == nil {
+		return nil, false
+	}
+	pi := &PackageIndex{}
+	if !idx.pass.ImportPackageFact(obj.Pkg(), pi) {
+		return nil, false
+	}
+	di, ok := pi.Decls[DeclKey(obj)]
+	return di, ok
+}
@@ -0,0 +1,148 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/unitchecker"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// TestMain 环境变量 ZPASS_VETTOOL=1 时，测试程序作为 go vet -vettool 运行 indexAnalyzer
+func TestMain(m *testing.M) {
+	if os.Getenv("ZPASS_VETTOOL") == "1" {
+		unitchecker.Main(indexAnalyzer)
+		panic("unreachable")
+	}
+	flag.Parse()
+	os.Exit(m.Run())
+}
+
+var indexContainer = &Container{}
+
+// indexAnalyzer 报告调用的函数定义所在的文件，以及是否 recover
+var indexAnalyzer = &analysis.Analyzer{
+	Name:     "zpass_index_demo",
+	Doc:      "report where the called funcs are declared",
+	Requires: []*analysis.Analyzer{NewInitAnalyzer(indexContainer)},
+	Run: func(pass *analysis.Pass) (any, error) {
+		for _, f := range pass.Files {
+			ast.Inspect(f, func(node ast.Node) bool {
+				ce, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				fn, ok := typeutil.Callee(pass.TypesInfo, ce).(*types.Func)
+				if !ok {
+					return true
+				}
+				file, err := indexContainer.FindAstFileByObject(pass, fn)
+				if err != nil {
+					pass.Reportf(ce.Pos(), "%v", err)
+					return true
+				}
+				di, ok := indexContainer.FindDecl(pass, fn)
+				if !ok {
+					pass.Reportf(ce.Pos(), "%s not in index", DeclKey(fn))
+					return true
+				}
+				tf := pass.Fset.File(file.Pos())
+				for _, decl := range file.Decls {
+					fd, ok := decl.(*ast.FuncDecl)
+					if ok && fd.Pos() == tf.Pos(di.Offset) && fd.Name.Name == fn.Name() {
+						pass.Reportf(ce.Pos(), "%s declared in %s, recover=%t", DeclKey(fn), filepath.Base(tf.Name()), di.Recover)
+						return true
+					}
+				}
+				pass.Reportf(ce.Pos(), "decl of %s not found in %s", DeclKey(fn), tf.Name())
+				return true
+			})
+		}
+		return nil, nil
+	},
+}
+
+func TestContainerIndex(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), indexAnalyzer, "index/b")
+}
+
+// TestContainerIndexVet 使用 go vet -vettool 运行，每个 package 在单独的进程中分析，
+// 依赖 package 中的定义只能通过 PackageIndex fact 查找
+func TestContainerIndexVet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go vet in short mode")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module index\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a/a.go", "b/b.go"} {
+		bf, err := os.ReadFile(filepath.Join(analysistest.TestData(), "src", "index", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		name = filepath.Join(dir, name)
+		if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(name, bf, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command("go", "vet", "-vettool="+exe, "-json", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "ZPASS_VETTOOL=1", "GOWORK=off", "GOFLAGS=")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go vet: %v\n%s", err, out)
+	}
+
+	// 输出为每个 package 一行 "# pkg"，之后是其 JSON 格式的诊断信息
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	got := make(map[string]string)
+	dec := json.NewDecoder(strings.NewReader(strings.Join(lines, "\n")))
+	for dec.More() {
+		var result map[string]map[string][]struct {
+			Posn    string `json:"posn"`
+			Message string `json:"message"`
+		}
+		if err = dec.Decode(&result); err != nil {
+			t.Fatalf("decode go vet output: %v\n%s", err, out)
+		}
+		for _, d := range result["index/b"][indexAnalyzer.Name] {
+			got[filepath.Base(d.Posn)] = d.Message
+		}
+	}
+	want := map[string]string{
+		"b.go:6:2": "Safe declared in a.go, recover=true",
+		"b.go:7:2": "Unsafe declared in a.go, recover=false",
+		"b.go:8:2": "T.Run declared in a.go, recover=false",
+		"b.go:9:2": "local declared in b.go, recover=false",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got diagnostics %q, want %q\n%s", got, want, out)
+	}
+}
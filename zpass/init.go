@@ -5,31 +5,30 @@
 package zpass
 
 import (
+	"reflect"
+
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 )
 
+// NewInitAnalyzer 创建导出 PackageIndex fact 的 analyzer，结果为 *Index，
+// 使用 Container.FindAstFileByObject、Container.FindDecl 的 analyzer 需要依赖它
 func NewInitAnalyzer(c *Container) *analysis.Analyzer {
-	return &analysis.Analyzer{
+	a := &analysis.Analyzer{
 		Name: "zpass_init",
-		Doc:  `add all pass to DefaultContainer`,
+		Doc:  `export the PackageIndex fact of every package`,
 		Requires: []*analysis.Analyzer{
 			inspect.Analyzer,
 			// findcall.Analyzer,
 		},
 		Run: func(pass *analysis.Pass) (any, error) {
-			tryParserFlags()
-			c.AddPass(pass)
-			return nil, nil
+			TryParseFlags()
+			exportIndex(pass)
+			return &Index{pass: pass}, nil
 		},
-		FactTypes: []analysis.Fact{new(foundFact)},
+		FactTypes:  []analysis.Fact{new(PackageIndex)},
+		ResultType: reflect.TypeOf(new(Index)),
 	}
+	c.initAnalyzer = a
+	return a
 }
-
-type foundFact struct{}
-
-func (*foundFact) String() string {
-	return "found"
-}
-
-func (*foundFact) AFact() {}
@@ -0,0 +1,112 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/multichecker"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+// Main 命令行程序的入口，运行 analyzers，为空时运行所有使用 Register 注册的 analyzer
+//
+// 可以使用 -enable、-disable 参数选择运行哪些 analyzer，
+// 作为 go vet -vettool 运行，或者使用 -fix 等参数时，使用 x/tools 的 singlechecker/multichecker
+//
+// 第一个参数为 cache 时是缓存的子命令，见 CacheMain
+func Main(analyzers ...*analysis.Analyzer) {
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		os.Exit(CacheMain(os.Args[2:]))
+	}
+	if len(analyzers) == 0 {
+		analyzers = Analyzers()
+	}
+	if len(analyzers) == 0 {
+		fmt.Fprintln(os.Stderr, "no analyzer registered")
+		os.Exit(1)
+	}
+	names := make([]string, 0, len(analyzers))
+	for _, a := range analyzers {
+		names = append(names, ShortName(a))
+	}
+	enable := flag.String("enable", "", "comma-separated list of analyzers to run, default is all: "+strings.Join(names, ", "))
+	disable := flag.String("disable", "", "comma-separated list of analyzers not to run")
+
+	args := os.Args[1:]
+	if StdDriverWanted(args) {
+		as, err := SelectAnalyzers(analyzers, splitList(lookupFlag(args, "enable")), splitList(lookupFlag(args, "disable")))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		RegisterFilterFlags()
+		RegisterPathStyleFlag()
+		ApplyFilter(as...)
+		// 参数的前缀和 RegisterFlags 保持一致
+		if len(analyzers) == 1 {
+			singlechecker.Main(as[0])
+		} else {
+			multichecker.Main(as...)
+		}
+		return
+	}
+
+	RegisterFlags(analyzers...)
+	TryParseFlags()
+	as, err := SelectAnalyzers(analyzers, splitList(*enable), splitList(*disable))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	g, err := Analyze(as, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(PrintDiagnostics(g))
+}
+
+// lookupFlag 在 flag.Parse 之前，从 args 中读取参数 name 的值，
+// 用于交给 x/tools 的 driver 解析参数之前，选择 analyzer
+func lookupFlag(args []string, name string) string {
+	var value string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		k, v, ok := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if k != name {
+			continue
+		}
+		if ok {
+			value = v
+		} else if i+1 < len(args) {
+			value = args[i+1]
+			i++
+		}
+	}
+	return value
+}
+
+// splitList 分割逗号分隔的列表
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
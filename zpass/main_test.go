@@ -0,0 +1,41 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookupFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"-enable=a,b", "./..."}, want: "a,b"},
+		{args: []string{"--enable", "a", "./..."}, want: "a"},
+		{args: []string{"-enable="}, want: ""},
+		// 多次指定时最后一个生效
+		{args: []string{"-enable=a", "-enable", "b"}, want: "b"},
+		{args: []string{"-enabled=a", "-disable=enable"}, want: ""},
+		{args: []string{"-enable"}, want: ""},
+		{args: []string{"./...", "enable"}, want: ""},
+		{args: []string{"--", "-enable=a"}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := lookupFlag(tt.args, "enable"); got != tt.want {
+			t.Errorf("lookupFlag(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	if got := splitList(""); got != nil {
+		t.Errorf("splitList(\"\") = %q, want nil", got)
+	}
+	if got, want := splitList("a,b"), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("splitList(\"a,b\") = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"flag"
+	"fmt"
+	"go/build"
+	"go/token"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// PathStyle 输出位置时文件路径的格式
+type PathStyle string
+
+const (
+	// PathModule 和当前目录无关的路径：
+	//   - 当前 module 中的文件，为相对 go.mod 所在目录的路径，如 "internal/a/a.go"
+	//   - 其他本地 module(如 go.work、replace 的)中的文件，为 module path 加相对路径，如 "example.com/lib/lib.go"
+	//   - module cache 中依赖的文件，为 module path@version 加相对路径，如 "golang.org/x/sync@v0.10.0/errgroup/errgroup.go"
+	//   - 标准库的文件，为 std@go版本 加相对路径，如 "std@go1.24.0/net/http/server.go"
+	//   - 不在 module 中的文件，为绝对路径
+	PathModule PathStyle = "module"
+
+	// PathRelative 相对当前目录的路径，如 "../a/a.go"
+	PathRelative PathStyle = "relative"
+
+	// PathAbsolute 绝对路径
+	PathAbsolute PathStyle = "absolute"
+)
+
+var pathStyles = []PathStyle{PathModule, PathRelative, PathAbsolute}
+
+var pathStyle = PathModule
+
+// RegisterPathStyleFlag 注册 -path-style 参数，RegisterFlags 会调用，
+// 使用 singlechecker 等 driver 的程序在其解析参数前调用
+func RegisterPathStyleFlag() {
+	if flag.Lookup("path-style") != nil {
+		return
+	}
+	flag.Var(&pathStyle, "path-style", "path style of positions in output: module|relative|absolute\nmodule paths are relative to the module root and independent of the current dir")
+}
+
+// SetPathStyle 设置输出位置时文件路径的格式，默认为 PathModule
+func SetPathStyle(s PathStyle) error {
+	return pathStyle.Set(string(s))
+}
+
+func (s *PathStyle) String() string {
+	return string(*s)
+}
+
+func (s *PathStyle) Set(value string) error {
+	for _, v := range pathStyles {
+		if string(v) == value {
+			*s = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported path style %q", value)
+}
+
+// RelPath 按照 -path-style 返回文件 name 的路径，name 也可以是 "file:line:col" 格式的位置
+func RelPath(name string) string {
+	return pathStyle.Render(name)
+}
+
+// FormatPosition 按照 -path-style 返回位置 "file:line:col"
+func FormatPosition(pos token.Position) string {
+	pos.Filename = RelPath(pos.Filename)
+	return pos.String()
+}
+
+// Render 按照格式 s 返回文件 name 的路径，name 也可以是 "file:line:col" 格式的位置
+func (s PathStyle) Render(name string) string {
+	if name == "" || !filepath.IsAbs(name) {
+		return name
+	}
+	switch s {
+	case PathAbsolute:
+		return name
+	case PathRelative:
+		return relToWd(name)
+	default:
+		return modulePath(name)
+	}
+}
+
+// key 用于缓存的 key，诊断信息中可能有按照格式输出的路径
+func (s PathStyle) key() string {
+	switch s {
+	case PathRelative:
+		return string(s) + " " + pathWd()
+	case PathModule:
+		root, _ := mainModule()
+		return string(s) + " " + root
+	default:
+		return string(s)
+	}
+}
+
+var pathWd = sync.OnceValue(func() string {
+	wd, _ := os.Getwd()
+	return wd
+})
+
+func relToWd(name string) string {
+	rel, err := filepath.Rel(pathWd(), name)
+	if err != nil {
+		return name
+	}
+	return rel
+}
+
+// mainModule 返回当前目录所在 module 的根目录和 module path
+var mainModule = sync.OnceValues(func() (root string, path string) {
+	return findModule(pathWd())
+})
+
+func modulePath(name string) string {
+	if rel, ok := underDir(modCacheDir(), name); ok {
+		// module cache 中的路径为 "转义后的 module path@version/文件"
+		if before, after, ok := strings.Cut(rel, "@"); ok {
+			if p, err := module.UnescapePath(before); err == nil {
+				rel = p + "@" + after
+			}
+		}
+		return rel
+	}
+	if rel, ok := underDir(filepath.Join(goRoot(), "src"), name); ok {
+		return "std@" + goVersion() + "/" + rel
+	}
+	root, mod := findModule(filepath.Dir(name))
+	if root == "" {
+		return name
+	}
+	rel, _ := underDir(root, name)
+	if main, _ := mainModule(); root == main || mod == "" {
+		return rel
+	}
+	return mod + "/" + rel
+}
+
+// underDir 若 name 在目录 dir 下，返回 / 分隔的相对路径
+func underDir(dir string, name string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(dir, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+type moduleInfo struct {
+	root string
+	path string
+}
+
+var modules sync.Map // dir -> moduleInfo
+
+// findModule 从目录 dir 向上查找 go.mod，返回其所在目录和 module path，找不到时返回空
+func findModule(dir string) (root string, path string) {
+	if v, ok := modules.Load(dir); ok {
+		mi := v.(moduleInfo)
+		return mi.root, mi.path
+	}
+	var mi moduleInfo
+	if content, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		mi = moduleInfo{root: dir, path: modfile.ModulePath(content)}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		mi.root, mi.path = findModule(parent)
+	}
+	modules.Store(dir, mi)
+	return mi.root, mi.path
+}
+
+var modCacheDir = sync.OnceValue(func() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	list := filepath.SplitList(build.Default.GOPATH)
+	if len(list) == 0 || list[0] == "" {
+		return ""
+	}
+	return filepath.Join(list[0], "pkg", "mod")
+})
+
+func goRoot() string {
+	return build.Default.GOROOT
+}
+
+// goVersion 返回 GOROOT 中 go 的版本，读取 VERSION 文件失败时为当前程序编译时的版本
+var goVersion = sync.OnceValue(func() string {
+	content, err := os.ReadFile(filepath.Join(goRoot(), "VERSION"))
+	if err == nil {
+		if line, _, _ := strings.Cut(string(content), "\n"); strings.HasPrefix(line, "go") {
+			return strings.TrimSpace(line)
+		}
+	}
+	return runtime.Version()
+})
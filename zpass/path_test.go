@@ -0,0 +1,76 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathStyleRender(t *testing.T) {
+	wd := pathWd()
+	root, _ := mainModule()
+	if root == "" {
+		t.Skip("not in a module")
+	}
+
+	lib := t.TempDir()
+	if err := os.WriteFile(filepath.Join(lib, "go.mod"), []byte("module example.com/lib\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	noMod := filepath.Join(t.TempDir(), "a.go")
+
+	type renderTest struct {
+		style PathStyle
+		name  string
+		want  string
+	}
+	tests := []renderTest{
+		{style: PathModule, name: "", want: ""},
+		{style: PathModule, name: "a/b.go:1:2", want: "a/b.go:1:2"},
+		{style: PathModule, name: filepath.Join(wd, "path.go"), want: "zpass/path.go"},
+		{style: PathModule, name: filepath.Join(wd, "path.go:3:4"), want: "zpass/path.go:3:4"},
+		{style: PathModule, name: filepath.Join(lib, "a", "b.go:3:4"), want: "example.com/lib/a/b.go:3:4"},
+		{
+			style: PathModule,
+			name:  filepath.Join(goRoot(), "src", "net", "http", "server.go"),
+			want:  "std@" + goVersion() + "/net/http/server.go",
+		},
+		{style: PathRelative, name: filepath.Join(wd, "path.go:3:4"), want: "path.go:3:4"},
+		{style: PathRelative, name: filepath.Join(root, "go.mod"), want: filepath.Join("..", "go.mod")},
+		{style: PathRelative, name: "a/b.go", want: "a/b.go"},
+		{style: PathAbsolute, name: filepath.Join(wd, "path.go:3:4"), want: filepath.Join(wd, "path.go:3:4")},
+	}
+	if dir := modCacheDir(); dir != "" {
+		tests = append(tests, renderTest{
+			// module path 在 module cache 中是转义后的
+			style: PathModule,
+			name:  filepath.Join(dir, "github.com", "!burnt!sushi", "toml@v1.4.0", "decode.go:1:1"),
+			want:  "github.com/BurntSushi/toml@v1.4.0/decode.go:1:1",
+		})
+	}
+	if r, _ := findModule(filepath.Dir(noMod)); r == "" {
+		// 不在 module 中
+		tests = append(tests, renderTest{style: PathModule, name: noMod, want: noMod})
+	}
+	for _, tt := range tests {
+		if got := tt.style.Render(tt.name); got != tt.want {
+			t.Errorf("%s Render(%q) = %q, want %q", tt.style, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPathStyleSet(t *testing.T) {
+	var s PathStyle
+	for _, v := range []string{"module", "relative", "absolute"} {
+		if err := s.Set(v); err != nil || string(s) != v {
+			t.Errorf("Set(%q): got %q, %v", v, s, err)
+		}
+	}
+	if err := s.Set("rel"); err == nil {
+		t.Error("Set(rel): want error")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+// Package plugin golangci-lint 的 module plugin，运行所有使用 zpass.Register 注册的 analyzer
+//
+// .golangci.yml 中的配置：
+//
+//	linters-settings:
+//	  custom:
+//	    zpass:
+//	      type: module
+//	      settings:
+//	        enable: [go_recover]
+//	        analyzers:
+//	          go_recover:
+//	            tests: warn
+//	            trusted-modules: [std, golang.org/x]
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/plugin-module-register/register"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/fsgo/gocode/zpass"
+
+	// 注册 analyzer
+	_ "github.com/fsgo/gocode/zanalysis/zpasses/gorecover"
+)
+
+// Name 插件的名字，即 .golangci.yml 中 linters-settings.custom 下的名字
+const Name = "zpass"
+
+func init() {
+	register.Plugin(Name, New)
+}
+
+// Settings 插件的配置
+type Settings struct {
+	// Enable 启用的 analyzer，为空时启用全部，名字可以省略 "zpass_" 前缀
+	Enable []string `json:"enable"`
+
+	// Disable 不启用的 analyzer
+	Disable []string `json:"disable"`
+
+	// Debug 和 -debug 参数一致
+	Debug string `json:"debug"`
+
+	// Exclude、Include 和 -exclude、-include 参数一致，不检查/只检查匹配的文件
+	Exclude []string `json:"exclude"`
+	Include []string `json:"include"`
+
+	// Analyzers analyzer 的参数，analyzer 名字 -> 参数名 -> 值，
+	// 参数和命令行的一致，如 go_recover 的 tests、trusted-modules，列表会使用逗号连接
+	Analyzers map[string]map[string]any `json:"analyzers"`
+}
+
+// New 创建插件，golangci-lint 使用
+func New(conf any) (register.LinterPlugin, error) {
+	s, err := register.DecodeSettings[Settings](conf)
+	if err != nil {
+		return nil, err
+	}
+	// 参数都来自配置，不能解析 golangci-lint 的命令行参数
+	zpass.DisableParseFlags()
+	if s.Debug != "" {
+		zpass.SetDebug(s.Debug)
+	}
+	all := zpass.Analyzers()
+	for name, flags := range s.Analyzers {
+		a := zpass.FindAnalyzer(all, name)
+		if a == nil {
+			return nil, fmt.Errorf("unknown analyzer %q", name)
+		}
+		if err = setFlags(a, flags); err != nil {
+			return nil, err
+		}
+	}
+	as, err := zpass.SelectAnalyzers(all, s.Enable, s.Disable)
+	if err != nil {
+		return nil, err
+	}
+	zpass.DefaultFilter.Exclude = append(zpass.DefaultFilter.Exclude, s.Exclude...)
+	zpass.DefaultFilter.Include = append(zpass.DefaultFilter.Include, s.Include...)
+	zpass.ApplyFilter(as...)
+	return &plugin{analyzers: as}, nil
+}
+
+type plugin struct {
+	analyzers []*analysis.Analyzer
+}
+
+func (p *plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	return p.analyzers, nil
+}
+
+func (p *plugin) GetLoadMode() string {
+	return register.LoadModeTypesInfo
+}
+
+// setFlags 将配置设置到 analyzer 的参数上，值为 null 的使用默认值，
+// 空列表(如 trusted-modules: [])为空字符串，和命令行的 -trusted-modules= 一致
+func setFlags(a *analysis.Analyzer, flags map[string]any) error {
+	for name, value := range flags {
+		if a.Flags.Lookup(name) == nil {
+			return fmt.Errorf("analyzer %s has no flag %q", a.Name, name)
+		}
+		if value == nil {
+			continue
+		}
+		str, err := flagValue(value)
+		if err != nil {
+			return fmt.Errorf("analyzer %s flag %q: %w", a.Name, name, err)
+		}
+		if err = a.Flags.Set(name, str); err != nil {
+			return fmt.Errorf("analyzer %s flag %q: %w", a.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// flagValue 将 yaml/json 中的值转换为参数的值
+func flagValue(value any) (string, error) {
+	switch vt := value.(type) {
+	case string:
+		return vt, nil
+	case bool:
+		return strconv.FormatBool(vt), nil
+	case float64:
+		return strconv.FormatFloat(vt, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, 0, len(vt))
+		for _, v := range vt {
+			item, err := flagValue(v)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
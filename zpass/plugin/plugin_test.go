@@ -0,0 +1,147 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package plugin
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestFlagValue(t *testing.T) {
+	tests := []struct {
+		value   any
+		want    string
+		wantErr bool
+	}{
+		{value: "warn", want: "warn"},
+		{value: true, want: "true"},
+		{value: float64(3), want: "3"},
+		{value: 1.5, want: "1.5"},
+		{value: []any{"std", "golang.org/x"}, want: "std,golang.org/x"},
+		{value: []any{}, want: ""},
+		{value: []any{"a", []any{1}}, wantErr: true},
+		{value: map[string]any{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := flagValue(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("flagValue(%#v): err = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("flagValue(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// listValue 逗号分隔的列表参数，和 analyzer 中的列表参数一样，空字符串表示没有值
+type listValue []string
+
+func (l *listValue) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+func TestSetFlags(t *testing.T) {
+	newAnalyzer := func() (*analysis.Analyzer, *listValue) {
+		a := &analysis.Analyzer{Name: "demo"}
+		list := &listValue{}
+		a.Flags.Var(list, "list", "")
+		a.Flags.String("mode", "ast", "")
+		a.Flags.Int("depth", 5, "")
+		a.Flags.Bool("swallow", true, "")
+		return a, list
+	}
+
+	a, list := newAnalyzer()
+	err := setFlags(a, map[string]any{
+		"list":    []any{"std", "golang.org/x"},
+		"mode":    "ssa",
+		"depth":   float64(3),
+		"swallow": false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"list": "std,golang.org/x", "mode": "ssa", "depth": "3", "swallow": "false"}
+	a.Flags.VisitAll(func(f *flag.Flag) {
+		if got := f.Value.String(); got != want[f.Name] {
+			t.Errorf("flag %s = %q, want %q", f.Name, got, want[f.Name])
+		}
+	})
+
+	// 空列表和 null 都不报错
+	a, list = newAnalyzer()
+	if err = setFlags(a, map[string]any{"list": []any{}, "mode": nil}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*list) != 0 || a.Flags.Lookup("mode").Value.String() != "ast" {
+		t.Errorf("got list %v, mode %s, want empty and default", *list, a.Flags.Lookup("mode").Value)
+	}
+
+	errTests := []map[string]any{
+		{"unknown": "x"},
+		{"depth": "x"},
+		{"mode": map[string]any{}},
+	}
+	for _, flags := range errTests {
+		a, _ = newAnalyzer()
+		if err = setFlags(a, flags); err == nil {
+			t.Errorf("setFlags(%v): want error", flags)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	errTests := []any{
+		map[string]any{"unknown": true},
+		map[string]any{"enable": []any{"not_exists"}},
+		map[string]any{"analyzers": map[string]any{"not_exists": map[string]any{}}},
+		map[string]any{"analyzers": map[string]any{"go_recover": map[string]any{"not_exists": 1}}},
+		map[string]any{"analyzers": map[string]any{"go_recover": map[string]any{"tests": "x"}}},
+	}
+	for _, conf := range errTests {
+		if _, err := New(conf); err == nil {
+			t.Errorf("New(%v): want error", conf)
+		}
+	}
+
+	p, err := New(map[string]any{
+		"enable": []any{"go_recover"},
+		"analyzers": map[string]any{
+			"go_recover": map[string]any{
+				"tests":           "warn",
+				"trusted-modules": []any{},
+				"safe-funcs":      []any{"example.com/safe.Go"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	as, err := p.BuildAnalyzers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 1 || as[0].Name != "zpass_go_recover" {
+		t.Fatalf("got analyzers %v, want zpass_go_recover", as)
+	}
+	for name, want := range map[string]string{"tests": "warn", "trusted-modules": "", "safe-funcs": "example.com/safe.Go"} {
+		if got := as[0].Flags.Lookup(name).Value.String(); got != want {
+			t.Errorf("flag %s = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+var (
+	cpuProfile string
+	memProfile string
+)
+
+// PackageProfile 一个 analyzer 在一个 package 上的执行情况
+//
+// Allocs、Objects 是执行期间整个进程的内存分配，并行执行时包含其他 analyzer 的，
+// 需要准确的值时使用 -debug tp 顺序执行，依赖的 analyzer(如 inspect)只有耗时，没有内存分配的数据
+type PackageProfile struct {
+	Analyzer string
+	Package  string
+	Duration time.Duration
+	Allocs   uint64 // 分配的内存，单位字节
+	Objects  uint64 // 分配的对象数
+	Nodes    int    // package 的 AST 节点数
+}
+
+// profiler 记录每个 analyzer 的 Run 在每个 package 上的执行情况
+type profiler struct {
+	mu       sync.Mutex
+	profiles []PackageProfile
+	wrapped  map[*analysis.Analyzer]bool
+	nodes    sync.Map // package path -> AST 节点数
+}
+
+var defaultProfiler = &profiler{wrapped: make(map[*analysis.Analyzer]bool)}
+
+// profileWanted 是否需要记录执行情况：-debug t、-vv，或者指定了 -cpuprofile
+func profileWanted() bool {
+	return IsDebugTiming() || IsTrace() || cpuProfile != ""
+}
+
+// wrap 替换 analyzers 的 Run，每个 analyzer 只会替换一次
+//
+// 不替换依赖的 analyzer(如 inspect)，它们是共享的，不能修改，其耗时见 addDeps
+func (p *profiler) wrap(analyzers []*analysis.Analyzer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, a := range analyzers {
+		if p.wrapped[a] {
+			continue
+		}
+		p.wrapped[a] = true
+		a.Run = p.run(a, a.Run)
+	}
+}
+
+// addDeps 添加 g 中依赖的 analyzer(没有被 wrap 的)的执行情况，
+// 耗时为 checker 记录的，没有内存分配的数据
+func (p *profiler) addDeps(g *checker.Graph) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	g.All()(func(act *checker.Action) bool {
+		if !p.wrapped[act.Analyzer] {
+			p.profiles = append(p.profiles, PackageProfile{
+				Analyzer: act.Analyzer.Name,
+				Package:  act.Package.PkgPath,
+				Duration: act.Duration,
+				Nodes:    p.countNodes(act.Package.PkgPath, act.Package.Syntax),
+			})
+		}
+		return true
+	})
+}
+
+// run 返回记录执行情况的 Run，CPU profile 中的采样带有 analyzer、package 标签，
+// 可以使用 go tool pprof -tagfocus 查看
+func (p *profiler) run(a *analysis.Analyzer, run func(*analysis.Pass) (any, error)) func(*analysis.Pass) (any, error) {
+	return func(pass *analysis.Pass) (result any, err error) {
+		before := readAllocs()
+		start := time.Now()
+		labels := pprof.Labels("analyzer", a.Name, "package", pass.Pkg.Path())
+		pprof.Do(context.Background(), labels, func(context.Context) {
+			result, err = run(pass)
+		})
+		pp := PackageProfile{
+			Analyzer: a.Name,
+			Package:  pass.Pkg.Path(),
+			Duration: time.Since(start),
+			Nodes:    p.countNodes(pass.Pkg.Path(), pass.Files),
+		}
+		after := readAllocs()
+		pp.Allocs = after[0] - before[0]
+		pp.Objects = after[1] - before[1]
+		if IsTrace() {
+			log.Printf("[%s] done pkg: %s, cost=%s, allocs=%s\n", a.Name, pass.Pkg.Path(), pp.Duration, formatBytes(pp.Allocs))
+		}
+		p.mu.Lock()
+		p.profiles = append(p.profiles, pp)
+		p.mu.Unlock()
+		return result, err
+	}
+}
+
+func (p *profiler) countNodes(pkgPath string, files []*ast.File) int {
+	if v, ok := p.nodes.Load(pkgPath); ok {
+		return v.(int)
+	}
+	var num int
+	for _, f := range files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			if node != nil {
+				num++
+			}
+			return true
+		})
+	}
+	p.nodes.Store(pkgPath, num)
+	return num
+}
+
+var allocMetrics = []string{"/gc/heap/allocs:bytes", "/gc/heap/allocs:objects"}
+
+// readAllocs 返回进程累计分配的内存字节数和对象数
+func readAllocs() [2]uint64 {
+	samples := make([]metrics.Sample, len(allocMetrics))
+	for i, name := range allocMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	var result [2]uint64
+	for i, s := range samples {
+		if s.Value.Kind() == metrics.KindUint64 {
+			result[i] = s.Value.Uint64()
+		}
+	}
+	return result
+}
+
+// Profiles 返回已记录的执行情况，按耗时倒序排列，
+// 只有在 Analyze 执行时，指定了 -debug t、-vv 或者 -cpuprofile 才会记录
+func Profiles() []PackageProfile {
+	p := defaultProfiler
+	p.mu.Lock()
+	ps := append([]PackageProfile(nil), p.profiles...)
+	p.mu.Unlock()
+	sort.SliceStable(ps, func(i, j int) bool {
+		return ps[i].Duration > ps[j].Duration
+	})
+	return ps
+}
+
+// WriteProfile 输出执行情况的报告：每个 analyzer 的汇总，以及耗时最多的 top 个 package
+func WriteProfile(w io.Writer, top int) error {
+	ps := Profiles()
+	type total struct {
+		PackageProfile
+		packages int
+	}
+	totals := make(map[string]*total)
+	var names []string
+	for _, pp := range ps {
+		t, ok := totals[pp.Analyzer]
+		if !ok {
+			t = &total{PackageProfile: PackageProfile{Analyzer: pp.Analyzer}}
+			totals[pp.Analyzer] = t
+			names = append(names, pp.Analyzer)
+		}
+		t.packages++
+		t.Duration += pp.Duration
+		t.Allocs += pp.Allocs
+		t.Objects += pp.Objects
+		t.Nodes += pp.Nodes
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return totals[names[i]].Duration > totals[names[j]].Duration
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Analyzer\tPackages\tTime\tAllocs\tObjects\tNodes")
+	for _, name := range names {
+		t := totals[name]
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\t%d\n", name, t.packages, formatDuration(t.Duration), formatBytes(t.Allocs), t.Objects, t.Nodes)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Analyzer\tPackage\tTime\tAllocs\tObjects\tNodes")
+	for i, pp := range ps {
+		if i >= top {
+			break
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\n", pp.Analyzer, pp.Package, formatDuration(pp.Duration), formatBytes(pp.Allocs), pp.Objects, pp.Nodes)
+	}
+	return tw.Flush()
+}
+
+// startProfile 按照参数开始记录执行情况，返回的函数用于结束记录，输出报告和 profile 文件，
+// 其参数 g 为执行的结果，用于记录依赖的 analyzer 的执行情况，执行失败时为 nil
+func startProfile(analyzers []*analysis.Analyzer) (stop func(g *checker.Graph) error, err error) {
+	if !profileWanted() && memProfile == "" {
+		return func(*checker.Graph) error { return nil }, nil
+	}
+	if profileWanted() {
+		defaultProfiler.wrap(analyzers)
+	}
+	var cpuFile *os.File
+	if cpuProfile != "" {
+		if cpuFile, err = os.Create(cpuProfile); err != nil {
+			return nil, err
+		}
+		if err = pprof.StartCPUProfile(cpuFile); err != nil {
+			_ = cpuFile.Close()
+			return nil, err
+		}
+	}
+	stop = func(g *checker.Graph) error {
+		if g != nil && profileWanted() {
+			defaultProfiler.addDeps(g)
+		}
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				return err
+			}
+		}
+		if memProfile != "" {
+			if err := writeHeapProfile(memProfile); err != nil {
+				return err
+			}
+		}
+		if IsDebugTiming() {
+			return WriteProfile(os.Stderr, 20)
+		}
+		return nil
+	}
+	return stop, nil
+}
+
+func writeHeapProfile(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	runtime.GC()
+	if err = pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,124 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[uint64]string{
+		0:             "0B",
+		1023:          "1023B",
+		1024:          "1.0KB",
+		1536:          "1.5KB",
+		5 << 20:       "5.0MB",
+		3 << 30:       "3.0GB",
+		1<<40 + 1<<39: "1.5TB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		1234567890 * time.Nanosecond: "1.235s",
+		12345678 * time.Nanosecond:   "12.35ms",
+		1234 * time.Nanosecond:       "1µs",
+	}
+	for d, want := range tests {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%d) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestProfiler(t *testing.T) {
+	p := &profiler{wrapped: make(map[*analysis.Analyzer]bool)}
+	inspectRun := reflect.ValueOf(inspect.Analyzer.Run).Pointer()
+	a := &analysis.Analyzer{
+		Name:       "zpass_profile_demo",
+		Doc:        "profile demo",
+		Requires:   []*analysis.Analyzer{inspect.Analyzer},
+		ResultType: reflect.TypeOf(0),
+		Run: func(*analysis.Pass) (any, error) {
+			time.Sleep(time.Millisecond)
+			return 1, nil
+		},
+	}
+	p.wrap([]*analysis.Analyzer{a})
+	// 重复调用不会再次替换
+	p.wrap([]*analysis.Analyzer{a})
+	if len(p.wrapped) != 1 || !p.wrapped[a] {
+		t.Fatalf("got wrapped %v, want only %s", p.wrapped, a.Name)
+	}
+	// 依赖的 analyzer 是共享的，不修改
+	if reflect.ValueOf(inspect.Analyzer.Run).Pointer() != inspectRun {
+		t.Fatal("inspect.Analyzer.Run changed by wrap")
+	}
+
+	g, err := checker.Analyze([]*analysis.Analyzer{a}, loadDemoPackage(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Roots[0].Err != nil || g.Roots[0].Result != 1 {
+		t.Fatalf("got %v, %v, want 1, nil", g.Roots[0].Result, g.Roots[0].Err)
+	}
+	p.addDeps(g)
+	if len(p.profiles) != 2 {
+		t.Fatalf("got profiles %+v, want 2", p.profiles)
+	}
+	// File、Ident、GenDecl、ValueSpec、Ident、BasicLit
+	pp := p.profiles[0]
+	if pp.Analyzer != a.Name || pp.Package != "example.com/demo" || pp.Nodes != 6 || pp.Duration < time.Millisecond {
+		t.Fatalf("got profile %+v", pp)
+	}
+	if dep := p.profiles[1]; dep.Analyzer != inspect.Analyzer.Name || dep.Package != "example.com/demo" || dep.Nodes != 6 {
+		t.Fatalf("got dependency profile %+v", dep)
+	}
+}
+
+func TestWriteProfile(t *testing.T) {
+	p := defaultProfiler
+	p.mu.Lock()
+	old := p.profiles
+	p.profiles = []PackageProfile{
+		{Analyzer: "a", Package: "p1", Duration: time.Millisecond, Allocs: 1024, Objects: 1, Nodes: 10},
+		{Analyzer: "b", Package: "p1", Duration: 5 * time.Millisecond, Allocs: 2048, Objects: 2, Nodes: 10},
+		{Analyzer: "a", Package: "p2", Duration: 2 * time.Millisecond, Allocs: 1024, Objects: 1, Nodes: 20},
+	}
+	p.mu.Unlock()
+	t.Cleanup(func() {
+		p.mu.Lock()
+		p.profiles = old
+		p.mu.Unlock()
+	})
+
+	bf := &strings.Builder{}
+	if err := WriteProfile(bf, 2); err != nil {
+		t.Fatal(err)
+	}
+	want := `Analyzer  Packages  Time  Allocs  Objects  Nodes
+b         1         5ms   2.0KB   2        10
+a         2         3ms   2.0KB   2        30
+
+Analyzer  Package  Time  Allocs  Objects  Nodes
+b         p1       5ms   2.0KB   2        10
+a         p2       2ms   1.0KB   1        20
+`
+	if got := bf.String(); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
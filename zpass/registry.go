@@ -0,0 +1,98 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// DefaultContainer 所有 analyzer 共享的 Container
+var DefaultContainer = &Container{}
+
+var registry struct {
+	mu        sync.Mutex
+	analyzers []*analysis.Analyzer
+}
+
+// Register 注册 analyzer，cmd/zpass 等使用 Main 的程序会运行所有已注册的 analyzer
+// 一般在 analyzer 所在 package 的 init 中调用，名字重复时 panic
+func Register(a *analysis.Analyzer) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, item := range registry.analyzers {
+		if item.Name == a.Name {
+			panic(fmt.Sprintf("zpass: analyzer %q registered twice", a.Name))
+		}
+	}
+	registry.analyzers = append(registry.analyzers, a)
+}
+
+// Analyzers 返回所有已注册的 analyzer，按名字排序
+func Analyzers() []*analysis.Analyzer {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	as := slices.Clone(registry.analyzers)
+	slices.SortFunc(as, func(a, b *analysis.Analyzer) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return as
+}
+
+// ShortName 返回 analyzer 去掉 "zpass_" 前缀的名字，如 zpass_go_recover 为 go_recover
+func ShortName(a *analysis.Analyzer) string {
+	return strings.TrimPrefix(a.Name, "zpass_")
+}
+
+// FindAnalyzer 按照名字查找 analyzer，名字可以是全名或者 ShortName
+func FindAnalyzer(analyzers []*analysis.Analyzer, name string) *analysis.Analyzer {
+	idx := slices.IndexFunc(analyzers, func(a *analysis.Analyzer) bool {
+		return a.Name == name || ShortName(a) == name
+	})
+	if idx < 0 {
+		return nil
+	}
+	return analyzers[idx]
+}
+
+// SelectAnalyzers 选择启用的 analyzer，名字可以是全名或者 ShortName，enable 为空时表示全部
+func SelectAnalyzers(analyzers []*analysis.Analyzer, enable []string, disable []string) ([]*analysis.Analyzer, error) {
+	find := func(names []string) (map[*analysis.Analyzer]bool, error) {
+		found := make(map[*analysis.Analyzer]bool)
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			a := FindAnalyzer(analyzers, name)
+			if a == nil {
+				return nil, fmt.Errorf("unknown analyzer %q", name)
+			}
+			found[a] = true
+		}
+		return found, nil
+	}
+	enabled, err := find(enable)
+	if err != nil {
+		return nil, err
+	}
+	disabled, err := find(disable)
+	if err != nil {
+		return nil, err
+	}
+	var as []*analysis.Analyzer
+	for _, a := range analyzers {
+		if (len(enabled) == 0 || enabled[a]) && !disabled[a] {
+			as = append(as, a)
+		}
+	}
+	if len(as) == 0 {
+		return nil, fmt.Errorf("no analyzer enabled")
+	}
+	return as, nil
+}
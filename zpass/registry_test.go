@@ -0,0 +1,53 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestSelectAnalyzers(t *testing.T) {
+	a := &analysis.Analyzer{Name: "zpass_a"}
+	b := &analysis.Analyzer{Name: "zpass_b"}
+	c := &analysis.Analyzer{Name: "c"}
+	all := []*analysis.Analyzer{a, b, c}
+
+	tests := []struct {
+		name    string
+		enable  []string
+		disable []string
+		want    []*analysis.Analyzer
+		wantErr bool
+	}{
+		{name: "all", want: all},
+		{name: "enable short name", enable: []string{"a"}, want: []*analysis.Analyzer{a}},
+		{name: "enable full name", enable: []string{"zpass_b", " c "}, want: []*analysis.Analyzer{b, c}},
+		{name: "keep order", enable: []string{"c", "a"}, want: []*analysis.Analyzer{a, c}},
+		{name: "empty names", enable: []string{"", "a"}, disable: []string{""}, want: []*analysis.Analyzer{a}},
+		{name: "disable", disable: []string{"zpass_a"}, want: []*analysis.Analyzer{b, c}},
+		{name: "enable and disable", enable: []string{"a", "b"}, disable: []string{"b"}, want: []*analysis.Analyzer{a}},
+		{name: "unknown enable", enable: []string{"d"}, wantErr: true},
+		{name: "unknown disable", disable: []string{"zpass_c"}, wantErr: true},
+		{name: "none enabled", enable: []string{"a"}, disable: []string{"a"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectAnalyzers(all, tt.enable, tt.disable)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
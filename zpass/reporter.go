@@ -0,0 +1,184 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Severity 诊断信息的级别
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 错误，影响退出码
+	SeverityWarning Severity = "warning" // 警告，不影响退出码
+	SeverityInfo    Severity = "info"    // 提示，不影响退出码
+)
+
+func (s Severity) valid() bool {
+	switch s {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rule 诊断规则，Reporter 报告的每个诊断信息都属于一个规则
+type Rule struct {
+	// ID 规则的唯一 ID，如 "go_recover/unrecovered"，作为诊断信息的 Category，
+	// 以及 sarif 中的 ruleId
+	ID string
+
+	// Severity 默认级别，为空时为 SeverityError
+	Severity Severity
+
+	// Category 规则的分类，如 "reliability"，作为 sarif 中规则的 tag
+	Category string
+
+	// URL 规则的文档地址
+	URL string
+
+	// Doc 规则的一句话说明
+	Doc string
+}
+
+func (r *Rule) severity() Severity {
+	if r.Severity == "" {
+		return SeverityError
+	}
+	return r.Severity
+}
+
+var rules sync.Map // ID -> *Rule
+
+// RegisterRules 注册规则，输出 sarif 等格式时读取规则的信息，ID 重复时 panic
+// 一般在 analyzer 所在 package 的 init 中调用
+func RegisterRules(rs ...*Rule) {
+	for _, r := range rs {
+		if r.ID == "" || strings.Contains(r.ID, ":") || r.ID != strings.TrimSpace(r.ID) {
+			panic(fmt.Sprintf("zpass: invalid rule id %q", r.ID))
+		}
+		if r.Severity != "" && !r.Severity.valid() {
+			panic(fmt.Sprintf("zpass: rule %q has invalid severity %q", r.ID, r.Severity))
+		}
+		if _, loaded := rules.LoadOrStore(r.ID, r); loaded {
+			panic(fmt.Sprintf("zpass: rule %q registered twice", r.ID))
+		}
+	}
+}
+
+// LookupRule 查找规则，id 也可以是诊断信息的 Category
+func LookupRule(id string) *Rule {
+	id, _ = splitCategory(id)
+	if v, ok := rules.Load(id); ok {
+		return v.(*Rule)
+	}
+	return nil
+}
+
+// WithSeverity 返回使用级别 s 代替规则默认级别的 Category，格式为 "规则ID:级别"，
+// 如测试代码中的问题作为警告输出
+func WithSeverity(category string, s Severity) string {
+	id, _ := splitCategory(category)
+	if id == "" {
+		return string(s)
+	}
+	return id + ":" + string(s)
+}
+
+// SeverityOf 返回 Category 为 category 的诊断信息的级别：
+// 指定了级别时使用指定的，否则使用规则的默认级别，未注册的规则为 SeverityError
+//
+// 只有级别的 Category(如 CategoryWarning)也是合法的
+func SeverityOf(category string) Severity {
+	id, s := splitCategory(category)
+	if s != "" {
+		return s
+	}
+	if r := LookupRule(id); r != nil {
+		return r.severity()
+	}
+	return SeverityError
+}
+
+// splitCategory 将 "规则ID:级别" 格式的 Category 拆分为规则 ID 和级别
+func splitCategory(category string) (id string, s Severity) {
+	if Severity(category).valid() {
+		return "", Severity(category)
+	}
+	// 规则 ID 中没有 ":"，级别在最后
+	if i := strings.LastIndex(category, ":"); i >= 0 && Severity(category[i+1:]).valid() {
+		return category[:i], Severity(category[i+1:])
+	}
+	return category, ""
+}
+
+// Reporter 按照规则报告诊断信息，代替直接调用 pass.Report、pass.Reportf：
+//   - Category 为规则的 ID，URL 为规则的文档地址
+//   - Message 只保留一行，其余的行(如代码片段)移到 Related 中
+//
+// 每次报告时才读取 pass.Report，所以对 pass.Report 的替换(如过滤)依然有效
+type Reporter struct {
+	pass *analysis.Pass
+}
+
+// NewReporter 创建 pass 的 Reporter
+func NewReporter(pass *analysis.Pass) *Reporter {
+	return &Reporter{pass: pass}
+}
+
+// Report 报告规则 rule 的诊断信息 d，d.Category 为空时设置为规则的 ID
+func (r *Reporter) Report(rule *Rule, d analysis.Diagnostic) {
+	if d.Category == "" {
+		d.Category = rule.ID
+	}
+	if d.URL == "" {
+		d.URL = rule.URL
+	}
+	msg, more := singleLine(d.Message)
+	d.Message = msg
+	if more != "" {
+		d.Related = append(slices.Clip(d.Related), analysis.RelatedInformation{
+			Pos:     d.Pos,
+			End:     d.End,
+			Message: more,
+		})
+	}
+	r.pass.Report(d)
+}
+
+// Reportf 报告规则 rule 在 rng 处的诊断信息
+func (r *Reporter) Reportf(rule *Rule, rng analysis.Range, format string, args ...any) {
+	r.Report(rule, analysis.Diagnostic{
+		Pos:     rng.Pos(),
+		End:     rng.End(),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+var colorReg = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// singleLine 去掉颜色，返回第一行和剩余的内容
+func singleLine(msg string) (line string, more string) {
+	msg = colorReg.ReplaceAllString(msg, "")
+	line, more, _ = strings.Cut(msg, "\n")
+	return strings.TrimSpace(line), strings.TrimRight(more, " \t\n")
+}
+
+// Related 返回 rng 处的相关信息，如代码片段、recover() 的位置
+func Related(rng analysis.Range, format string, args ...any) analysis.RelatedInformation {
+	return analysis.RelatedInformation{
+		Pos:     rng.Pos(),
+		End:     rng.End(),
+		Message: colorReg.ReplaceAllString(fmt.Sprintf(format, args...), ""),
+	}
+}
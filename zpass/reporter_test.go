@@ -0,0 +1,94 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import (
+	"testing"
+)
+
+var (
+	testRuleWarn    = &Rule{ID: "zpass_test_warn", Severity: SeverityWarning}
+	testRuleDefault = &Rule{ID: "zpass_test_default"}
+)
+
+func init() {
+	RegisterRules(testRuleWarn, testRuleDefault)
+}
+
+func TestWithSeverity(t *testing.T) {
+	tests := []struct {
+		category string
+		s        Severity
+		want     string
+	}{
+		{category: "zpass_test_warn", s: SeverityError, want: "zpass_test_warn:error"},
+		// 替换已指定的级别
+		{category: "zpass_test_warn:error", s: SeverityInfo, want: "zpass_test_warn:info"},
+		{category: "", s: SeverityWarning, want: "warning"},
+		{category: "error", s: SeverityWarning, want: "warning"},
+		{category: "a:b", s: SeverityInfo, want: "a:b:info"},
+	}
+	for _, tt := range tests {
+		if got := WithSeverity(tt.category, tt.s); got != tt.want {
+			t.Errorf("WithSeverity(%q, %q) = %q, want %q", tt.category, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	tests := []struct {
+		category string
+		want     Severity
+	}{
+		{category: "zpass_test_warn", want: SeverityWarning},
+		{category: "zpass_test_default", want: SeverityError},
+		{category: "zpass_test_warn:info", want: SeverityInfo},
+		{category: "zpass_test_default:warning", want: SeverityWarning},
+		{category: "warning", want: SeverityWarning},
+		{category: "", want: SeverityError},
+		{category: "not_registered", want: SeverityError},
+		{category: "zpass_test_warn:unknown", want: SeverityError},
+	}
+	for _, tt := range tests {
+		if got := SeverityOf(tt.category); got != tt.want {
+			t.Errorf("SeverityOf(%q) = %q, want %q", tt.category, got, tt.want)
+		}
+		// WithSeverity 之后 SeverityOf 返回指定的级别
+		if got := SeverityOf(WithSeverity(tt.category, SeverityInfo)); got != SeverityInfo {
+			t.Errorf("SeverityOf(WithSeverity(%q, info)) = %q, want info", tt.category, got)
+		}
+	}
+}
+
+func TestLookupRule(t *testing.T) {
+	for _, id := range []string{"zpass_test_warn", "zpass_test_warn:error"} {
+		if got := LookupRule(id); got != testRuleWarn {
+			t.Errorf("LookupRule(%q) = %v, want %v", id, got, testRuleWarn)
+		}
+	}
+	if got := LookupRule("not_registered"); got != nil {
+		t.Errorf("LookupRule(not_registered) = %v, want nil", got)
+	}
+}
+
+func TestRegisterRulesPanic(t *testing.T) {
+	tests := []*Rule{
+		{ID: ""},
+		{ID: "a:b"},
+		{ID: " a"},
+		{ID: "zpass_test_bad_severity", Severity: "fatal"},
+		{ID: "zpass_test_warn"},
+	}
+	for _, r := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterRules(%+v): want panic", r)
+				}
+			}()
+			RegisterRules(r)
+		}()
+	}
+}
@@ -0,0 +1,7 @@
+package cached
+
+func A() {} // want A:"cached" "func A"
+
+func B() {} // want B:"cached" "func B"
+
+func c() {} // want "func c"
@@ -0,0 +1,3 @@
+package filter // want "2 files, 2 pass files, required 4 files"
+
+func A() {} // want "func A"
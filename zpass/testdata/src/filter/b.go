@@ -0,0 +1,3 @@
+package filter
+
+func B() {} // want "func B"
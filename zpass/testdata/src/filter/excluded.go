@@ -0,0 +1,3 @@
+package filter
+
+func Excluded() {}
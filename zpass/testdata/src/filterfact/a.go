@@ -0,0 +1,3 @@
+package filterfact // want "1 files, 2 pass files, required 2 files"
+
+func A() {} // want "func A"
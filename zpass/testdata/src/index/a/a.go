@@ -0,0 +1,15 @@
+// Package a 被 index/b 调用，其中的定义通过 PackageIndex 查找
+package a
+
+// Safe recover 了 panic
+func Safe() {
+	defer func() {
+		_ = recover()
+	}()
+}
+
+func Unsafe() {}
+
+type T struct{}
+
+func (T) Run() {}
@@ -0,0 +1,12 @@
+package b
+
+import "index/a"
+
+func fn() {
+	a.Safe()    // want "Safe declared in a.go, recover=true"
+	a.Unsafe()  // want "Unsafe declared in a.go, recover=false"
+	a.T{}.Run() // want "T.Run declared in a.go, recover=false"
+	local()     // want "local declared in b.go, recover=false"
+}
+
+func local() {}
@@ -0,0 +1,43 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/18
+
+package zpass
+
+import "fmt"
+
+// TestMode 测试代码(_test.go 文件、xxx_test package)的检查方式，零值和 TestSkip 相同
+// 可以作为 flag.Value 使用
+type TestMode string
+
+const (
+	TestSkip   TestMode = "skip"   // 不检查
+	TestWarn   TestMode = "warn"   // 检查，问题作为警告输出，不影响退出码
+	TestReport TestMode = "report" // 检查，和其他代码一样报告
+)
+
+// CategoryWarning 警告类诊断信息的 Category，不影响退出码，
+// 使用 Reporter 报告的诊断信息可以使用 WithSeverity 保留规则 ID
+const CategoryWarning = string(SeverityWarning)
+
+// Skip 是否不检查测试代码
+func (m TestMode) Skip() bool {
+	return m == "" || m == TestSkip
+}
+
+func (m *TestMode) String() string {
+	if *m == "" {
+		return string(TestSkip)
+	}
+	return string(*m)
+}
+
+func (m *TestMode) Set(s string) error {
+	switch v := TestMode(s); v {
+	case TestSkip, TestWarn, TestReport:
+		*m = v
+		return nil
+	default:
+		return fmt.Errorf("invalid test mode %q, should be one of: skip, warn, report", s)
+	}
+}